@@ -0,0 +1,131 @@
+package vk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	methodSecureCheckToken         = "secure.checkToken"
+	methodAccountGetAppPermissions = "account.getAppPermissions"
+)
+
+// TokenInfo describes access token
+type TokenInfo struct {
+	UserID int
+	// Date when token was issued, zero if unknown
+	Date time.Time
+	// Expiry of token, zero if token is unlimited or expiry is unknown
+	Expiry time.Time
+	// Scope granted to application
+	Scope Scope
+}
+
+// ScopeError is returned when token lacks required permissions
+type ScopeError struct {
+	Missing []Permission
+}
+
+func (e ScopeError) Error() string {
+	missing := make([]string, len(e.Missing))
+	for i, p := range e.Missing {
+		missing[i] = p.String()
+	}
+	return fmt.Sprintf("token has no permissions: %s", strings.Join(missing, ","))
+}
+
+// Require returns ScopeError if any of permissions is not granted
+func (i TokenInfo) Require(permissions ...Permission) error {
+	var missing []Permission
+	for _, p := range permissions {
+		if !i.Scope.Has(p) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) != 0 {
+		return ScopeError{missing}
+	}
+	return nil
+}
+
+// unixTime returns time from unix timestamp, zero time for zero timestamp
+func unixTime(v int64) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(v, 0)
+}
+
+// Account is resource of account methods for token of client
+type Account struct {
+	Resource
+}
+
+// Secure is resource of secure methods, that
+// must be called with service token of application
+type Secure struct {
+	Resource
+}
+
+// CheckToken validates user token with secure.checkToken
+func (s Secure) CheckToken(ctx context.Context, token string) (info TokenInfo, err error) {
+	fields := struct {
+		Token string `url:"token"`
+	}{token}
+	result := struct {
+		Success int   `json:"success"`
+		UserID  int   `json:"user_id"`
+		Date    int64 `json:"date"`
+		Expire  int64 `json:"expire"`
+	}{}
+	if err = s.DecodeContext(ctx, s.Request(methodSecureCheckToken, fields), &result); err != nil {
+		return info, err
+	}
+	if result.Success != 1 {
+		return info, ErrAuthFailed
+	}
+	info.UserID = result.UserID
+	info.Date = unixTime(result.Date)
+	info.Expiry = unixTime(result.Expire)
+	return info, nil
+}
+
+// InspectToken validates user token with secure.checkToken and returns
+// its owner, issue date, expiry and scope, so caller can fail fast
+// on stale token or missing permissions
+func (s Secure) InspectToken(ctx context.Context, token string) (info TokenInfo, err error) {
+	if info, err = s.CheckToken(ctx, token); err != nil {
+		return info, err
+	}
+	account := Account{Resource{APIClient: s.APIClient, RequestFactory: Factory{token}}}
+	info.Scope, err = account.AppPermissions(ctx)
+	return info, err
+}
+
+// AppPermissions returns scope granted to token
+// with account.getAppPermissions
+func (a Account) AppPermissions(ctx context.Context) (Scope, error) {
+	var mask int
+	if err := a.DecodeContext(ctx, a.Request(methodAccountGetAppPermissions, nil), &mask); err != nil {
+		return nil, err
+	}
+	return ScopeFromMask(mask), nil
+}
+
+// Inspect validates token and returns its owner and scope, so caller
+// can fail fast on stale token or missing permissions. Owner is obtained
+// with users.get, use Secure.InspectToken to obtain issue date and expiry.
+func (a Account) Inspect(ctx context.Context) (info TokenInfo, err error) {
+	var users []User
+	if err = a.DecodeContext(ctx, a.Request(methodUsersGet, nil), &users); err != nil {
+		return info, err
+	}
+	if len(users) == 0 {
+		return info, ErrAuthFailed
+	}
+	info.UserID = users[0].ID
+	info.Scope, err = a.AppPermissions(ctx)
+	return info, err
+}
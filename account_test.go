@@ -0,0 +1,74 @@
+package vk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInspectToken(t *testing.T) {
+	Convey("Token inspection", t, func() {
+		const (
+			valid   = "valid"
+			service = "service"
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			token := query.Get(paramToken)
+			if token != valid && token != service {
+				fmt.Fprintf(w, `{"error": {"error_code": %d, "error_msg": "User authorization failed"}}`, ErrAuthFailed)
+				return
+			}
+			switch r.URL.Path {
+			case "/" + methodSecureCheckToken:
+				if token != service || query.Get("token") != valid {
+					w.Write([]byte(`{"error": {"error_code": 15, "error_msg": "Access denied"}}`))
+					return
+				}
+				w.Write([]byte(`{"response": {"success": 1, "user_id": 1, "date": 1600000000, "expire": 1600086400}}`))
+			case "/" + methodAccountGetAppPermissions:
+				w.Write([]byte(`{"response": 327682}`))
+			case "/" + methodUsersGet:
+				w.Write([]byte(`{"response": [{"id": 1, "first_name": "Павел"}]}`))
+			}
+		}))
+		defer server.Close()
+		options := []Option{WithBaseURL(server.URL), WithLimiter(nil), WithRetryPolicy(nil)}
+		account := func(token string) Account { return NewWithToken(token, options...).Account }
+		secure := NewWithToken(service, options...).Secure
+		ctx := context.Background()
+
+		Convey("With service token", func() {
+			info, err := secure.InspectToken(ctx, valid)
+			So(err, ShouldBeNil)
+			So(info.UserID, ShouldEqual, 1)
+			So(info.Date, ShouldEqual, time.Unix(1600000000, 0))
+			So(info.Expiry, ShouldEqual, time.Unix(1600086400, 0))
+			So(info.Scope, ShouldResemble, NewScope(PermFriends, PermOffline, PermGroups))
+		})
+		Convey("Without service token", func() {
+			info, err := account(valid).Inspect(ctx)
+			So(err, ShouldBeNil)
+			So(info.UserID, ShouldEqual, 1)
+			So(info.Expiry.IsZero(), ShouldBeTrue)
+			So(info.Require(PermGroups, PermOffline), ShouldBeNil)
+
+			err = info.Require(PermGroups, PermVideo, PermWall)
+			So(err, ShouldResemble, ScopeError{[]Permission{PermVideo, PermWall}})
+			So(err.Error(), ShouldEqual, "token has no permissions: video,wall")
+		})
+		Convey("Stale token", func() {
+			_, err := account("stale").Inspect(ctx)
+			So(errors.Is(err, ErrAuthFailed), ShouldBeTrue)
+			So(IsAuthError(err), ShouldBeTrue)
+			_, err = secure.InspectToken(ctx, "stale")
+			So(errors.Is(err, ErrNotAllowed), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,257 @@
+package vk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ernado-legacy/vk/vkscript"
+)
+
+const (
+	// maxExecuteCalls is vk limit of api calls in single execute
+	maxExecuteCalls    = vkscript.MaxCalls
+	defaultBatchWindow = time.Millisecond * 50
+)
+
+var (
+	// ErrBatchMismatch is returned to callers of batch, when
+	// execute response does not correspond to requests
+	ErrBatchMismatch = errors.New("execute response does not match batch")
+
+	jsonFalse = []byte("false")
+	jsonNull  = []byte("null")
+)
+
+// Batcher is APIClient that collects requests issued within short
+// window and sends them to underlying APIClient as single execute
+// call with up to 25 requests. Only requests with same access token
+// are batched together. It is safe for concurrent use.
+type Batcher struct {
+	client APIClient
+	window time.Duration
+
+	mux     sync.Mutex
+	pending map[string]*batch
+}
+
+// batchResult is result of single call of batch
+type batchResult struct {
+	response *Response
+	err      error
+}
+
+type batchCall struct {
+	ctx     context.Context
+	request Request
+	result  chan batchResult
+}
+
+type batch struct {
+	calls []batchCall
+	timer *time.Timer
+}
+
+// NewBatcher returns Batcher that sends requests to client,
+// waiting for window after first request of batch
+func NewBatcher(client APIClient, window time.Duration) *Batcher {
+	if window <= 0 {
+		window = defaultBatchWindow
+	}
+	return &Batcher{
+		client:  client,
+		window:  window,
+		pending: make(map[string]*batch),
+	}
+}
+
+// Do performs request with background context
+func (b *Batcher) Do(request Request) (*Response, error) {
+	return b.DoContext(context.Background(), request)
+}
+
+// DoContext adds request to batch and waits for its result.
+// Request is not sent if ctx is done before its batch; batch
+// already sent is cancelled only when all its callers are gone.
+func (b *Batcher) DoContext(ctx context.Context, request Request) (*Response, error) {
+	if request.Method == methodExecute {
+		// execute can not be nested
+		return b.client.DoContext(ctx, request)
+	}
+	call := batchCall{ctx: ctx, request: request, result: make(chan batchResult, 1)}
+	b.add(call)
+	select {
+	case r := <-call.result:
+		return r.response, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// add appends call to pending batch of its token
+func (b *Batcher) add(call batchCall) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	key := call.request.Token
+	p, ok := b.pending[key]
+	if !ok {
+		p = new(batch)
+		b.pending[key] = p
+		p.timer = time.AfterFunc(b.window, func() { b.flush(key, p) })
+	}
+	p.calls = append(p.calls, call)
+	if len(p.calls) >= maxExecuteCalls {
+		p.timer.Stop()
+		delete(b.pending, key)
+		go b.send(p.calls)
+	}
+}
+
+// flush sends batch after window, if it was not sent already
+func (b *Batcher) flush(key string, p *batch) {
+	b.mux.Lock()
+	if b.pending[key] != p {
+		b.mux.Unlock()
+		return
+	}
+	delete(b.pending, key)
+	b.mux.Unlock()
+	b.send(p.calls)
+}
+
+// send performs calls and delivers results to callers
+func (b *Batcher) send(calls []batchCall) {
+	// callers that are already gone are not sent
+	waiting := calls[:0]
+	for _, call := range calls {
+		if err := call.ctx.Err(); err != nil {
+			call.result <- batchResult{err: err}
+			continue
+		}
+		waiting = append(waiting, call)
+	}
+	calls = waiting
+	switch len(calls) {
+	case 0:
+		return
+	case 1:
+		res, err := b.client.DoContext(calls[0].ctx, calls[0].request)
+		calls[0].result <- batchResult{res, err}
+		return
+	}
+	ctx, cancel := batchContext(calls)
+	defer cancel()
+	results := b.execute(ctx, calls)
+	for i, call := range calls {
+		call.result <- results[i]
+	}
+}
+
+// batchContext returns context that is done when contexts of all calls
+// are done. If every call has deadline, context has the latest one.
+func batchContext(calls []batchCall) (context.Context, context.CancelFunc) {
+	var deadline time.Time
+	for _, call := range calls {
+		d, ok := call.ctx.Deadline()
+		if !ok {
+			deadline = time.Time{}
+			break
+		}
+		if d.After(deadline) {
+			deadline = d
+		}
+	}
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if deadline.IsZero() {
+		ctx, cancel = context.WithCancel(context.Background())
+	} else {
+		ctx, cancel = context.WithDeadline(context.Background(), deadline)
+	}
+	go func() {
+		for _, call := range calls {
+			select {
+			case <-call.ctx.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+	return ctx, cancel
+}
+
+// execute performs calls as single execute request
+func (b *Batcher) execute(ctx context.Context, calls []batchCall) []batchResult {
+	results := make([]batchResult, len(calls))
+	fail := func(err error) []batchResult {
+		for i := range results {
+			results[i].err = err
+		}
+		return results
+	}
+
+	items := make([]vkscript.Expr, len(calls))
+	for i, call := range calls {
+		items[i] = vkscript.CallValues(call.request.Method, call.request.Values)
+	}
+	script := new(vkscript.Script)
+	script.Return(vkscript.Array(items...))
+	code, err := script.Code()
+	if err != nil {
+		return fail(err)
+	}
+	values := url.Values{}
+	values.Set("code", code)
+	request := Request{
+		Method: methodExecute,
+		Token:  calls[0].request.Token,
+		Values: values,
+	}
+	res, err := b.client.DoContext(ctx, request)
+	var executeErrors Errors
+	if err != nil {
+		var ok bool
+		if executeErrors, ok = err.(Errors); !ok {
+			return fail(err)
+		}
+	}
+	if res == nil || len(res.Response) == 0 || bytes.Equal(res.Response, jsonNull) {
+		// execute failed as a whole, only its errors are returned
+		if executeErrors != nil {
+			return fail(executeErrors)
+		}
+		return fail(ErrBatchMismatch)
+	}
+	var responses []json.RawMessage
+	if err := json.Unmarshal(res.Response.Bytes(), &responses); err != nil {
+		return fail(err)
+	}
+	if len(responses) != len(calls) {
+		return fail(ErrBatchMismatch)
+	}
+	for i, call := range calls {
+		response := new(Response)
+		response.setRequest(call.request)
+		// failed calls are returned as false, their errors
+		// are listed in execute_errors in order of calls
+		if len(executeErrors) > 0 && bytes.Equal(responses[i], jsonFalse) &&
+			executeErrors[0].Method == call.request.Method {
+			response.Error.Code = executeErrors[0].Code
+			response.Error.Message = executeErrors[0].Message
+			executeErrors = executeErrors[1:]
+			results[i] = batchResult{response, response.Error}
+			continue
+		}
+		response.Response = Raw(responses[i])
+		results[i] = batchResult{response: response}
+	}
+	return results
+}
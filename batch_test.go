@@ -0,0 +1,263 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBatcher(t *testing.T) {
+	Convey("Batcher", t, func() {
+		var (
+			mux   sync.Mutex
+			codes []string
+			plain []string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mux.Lock()
+			defer mux.Unlock()
+			method := strings.TrimPrefix(r.URL.Path, "/")
+			if method != methodExecute {
+				plain = append(plain, method)
+				w.Write([]byte(`{"response": "plain"}`))
+				return
+			}
+			code := strings.TrimSpace(r.URL.Query().Get("code"))
+			codes = append(codes, code)
+			// every call is answered with its argument, groups.get fails
+			calls := strings.Split(strings.TrimSuffix(strings.TrimPrefix(code, "return ["), "];"), ",API.")
+			var (
+				items  []interface{}
+				errors Errors
+			)
+			for _, call := range calls {
+				if strings.Contains(call, "groups.get") {
+					items = append(items, false)
+					errors = append(errors, ExecuteError{Method: "groups.get", Code: ErrNotAllowed, Message: "Access denied"})
+					continue
+				}
+				start := strings.Index(call, `"id":"`) + len(`"id":"`)
+				items = append(items, call[start:strings.Index(call[start:], `"`)+start])
+			}
+			json.NewEncoder(w).Encode(struct {
+				Response []interface{} `json:"response"`
+				Errors   Errors        `json:"execute_errors,omitempty"`
+			}{items, errors})
+		}))
+		defer server.Close()
+
+		client := New(WithBaseURL(server.URL), WithLimiter(nil), WithRetryPolicy(nil))
+		batcher := NewBatcher(client, time.Millisecond*50)
+
+		type result struct {
+			id       string
+			response *Response
+			err      error
+		}
+		do := func(n int, method string, token string) []result {
+			results := make([]result, n)
+			wg := new(sync.WaitGroup)
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					id := strings.Repeat("1", i+1)
+					values := url.Values{}
+					values.Set("id", id)
+					res, err := batcher.Do(Request{Method: method, Token: token, Values: values})
+					results[i] = result{id, res, err}
+				}(i)
+			}
+			wg.Wait()
+			return results
+		}
+
+		Convey("Requests are coalesced", func() {
+			results := do(10, "users.get", "token")
+			So(codes, ShouldHaveLength, 1)
+			So(plain, ShouldBeEmpty)
+			for _, r := range results {
+				So(r.err, ShouldBeNil)
+				var id string
+				So(r.response.To(&id), ShouldBeNil)
+				So(id, ShouldEqual, r.id)
+				So(r.response.Error.Request.Values.Get("id"), ShouldEqual, r.id)
+			}
+		})
+		Convey("Batch is limited", func() {
+			results := do(maxExecuteCalls+5, "users.get", "token")
+			So(codes, ShouldHaveLength, 2)
+			for _, r := range results {
+				So(r.err, ShouldBeNil)
+			}
+			So(strings.Count(codes[0]+codes[1], "API.users.get"), ShouldEqual, maxExecuteCalls+5)
+		})
+		Convey("Tokens are not mixed", func() {
+			wg := new(sync.WaitGroup)
+			for _, token := range []string{"first", "second"} {
+				wg.Add(1)
+				go func(token string) {
+					defer wg.Done()
+					do(3, "users.get", token)
+				}(token)
+			}
+			wg.Wait()
+			So(codes, ShouldHaveLength, 2)
+		})
+		Convey("Execute errors are delivered to callers", func() {
+			wg := new(sync.WaitGroup)
+			var users, groups []result
+			wg.Add(2)
+			go func() { defer wg.Done(); users = do(2, "users.get", "token") }()
+			go func() { defer wg.Done(); groups = do(2, "groups.get", "token") }()
+			wg.Wait()
+			So(codes, ShouldHaveLength, 1)
+			for _, r := range users {
+				So(r.err, ShouldBeNil)
+			}
+			for _, r := range groups {
+				So(ErrNotAllowed.Is(r.err), ShouldBeTrue)
+				So(r.response.Error.Request.Method, ShouldEqual, "groups.get")
+			}
+		})
+		Convey("Single request is sent as is", func() {
+			results := do(1, "users.get", "token")
+			So(results[0].err, ShouldBeNil)
+			So(codes, ShouldBeEmpty)
+			So(plain, ShouldResemble, []string{"users.get"})
+		})
+		Convey("Cancellation", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := batcher.DoContext(ctx, Request{Method: "users.get"})
+			So(err, ShouldEqual, context.Canceled)
+		})
+	})
+}
+
+func TestBatcherContext(t *testing.T) {
+	Convey("Batcher context", t, func() {
+		received := make(chan struct{}, 1)
+		aborted := make(chan struct{}, 1)
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- struct{}{}
+			select {
+			case <-r.Context().Done():
+				aborted <- struct{}{}
+			case <-release:
+				w.Write([]byte(`{"response": [1, 2]}`))
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client := New(WithBaseURL(server.URL), WithLimiter(nil), WithRetryPolicy(nil))
+		batcher := NewBatcher(client, time.Millisecond*10)
+
+		start := func(ctx context.Context) chan error {
+			errs := make(chan error, 1)
+			go func() {
+				_, err := batcher.DoContext(ctx, Request{Method: "users.get", Token: "token"})
+				errs <- err
+			}()
+			return errs
+		}
+
+		Convey("Execute is cancelled when all callers are gone", func() {
+			ctx1, cancel1 := context.WithCancel(context.Background())
+			ctx2, cancel2 := context.WithCancel(context.Background())
+			errs1, errs2 := start(ctx1), start(ctx2)
+			<-received
+			cancel1()
+			So(<-errs1, ShouldEqual, context.Canceled)
+			select {
+			case <-aborted:
+				t.Error("execute aborted while caller waits")
+			case <-time.After(time.Millisecond * 50):
+			}
+			cancel2()
+			So(<-errs2, ShouldEqual, context.Canceled)
+			select {
+			case <-aborted:
+			case <-time.After(time.Second * 5):
+				t.Error("execute is not aborted")
+			}
+		})
+		Convey("Execute respects deadline of callers", func() {
+			ctx1, cancel1 := context.WithTimeout(context.Background(), time.Millisecond*50)
+			defer cancel1()
+			ctx2, cancel2 := context.WithTimeout(context.Background(), time.Millisecond*100)
+			defer cancel2()
+			errs1, errs2 := start(ctx1), start(ctx2)
+			So(errors.Is(<-errs1, context.DeadlineExceeded), ShouldBeTrue)
+			So(errors.Is(<-errs2, context.DeadlineExceeded), ShouldBeTrue)
+			select {
+			case <-aborted:
+			case <-time.After(time.Second * 5):
+				t.Error("execute is not aborted")
+			}
+		})
+		Convey("Gone callers are not sent", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			call := batchCall{ctx: ctx, request: Request{Method: "users.get"}, result: make(chan batchResult, 1)}
+			batcher.send([]batchCall{call})
+			So((<-call.result).err, ShouldEqual, context.Canceled)
+			So(len(received), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestBatcherExecuteErrors(t *testing.T) {
+	Convey("Batcher without execute response", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"execute_errors": [
+				{"method": "users.get", "error_code": 10, "error_msg": "Internal server error"},
+				{"method": "users.get", "error_code": 10, "error_msg": "Internal server error"}
+			]}`))
+		}))
+		defer server.Close()
+
+		do := func(batcher *Batcher) {
+			errs := make(chan error, 2)
+			for i := 0; i < 2; i++ {
+				go func() {
+					_, err := batcher.Do(Request{Method: "users.get", Token: "token"})
+					errs <- err
+				}()
+			}
+			for i := 0; i < 2; i++ {
+				So(errors.Is(<-errs, ErrInternalServerError), ShouldBeTrue)
+			}
+		}
+
+		Convey("Null response", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil), WithRetryPolicy(nil))
+			do(NewBatcher(client, time.Millisecond*10))
+		})
+		Convey("No response", func() {
+			do(NewBatcher(executeErrorsClient{}, time.Millisecond*10))
+		})
+	})
+}
+
+// executeErrorsClient fails every request with execute errors and no response
+type executeErrorsClient struct{}
+
+func (c executeErrorsClient) Do(req Request) (*Response, error) {
+	return c.DoContext(context.Background(), req)
+}
+
+func (executeErrorsClient) DoContext(ctx context.Context, req Request) (*Response, error) {
+	return nil, Errors{{Method: "users.get", Code: ErrInternalServerError}}
+}
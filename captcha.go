@@ -0,0 +1,32 @@
+package vk
+
+import (
+	"context"
+	"net/url"
+)
+
+// CaptchaSolver resolves captcha challenges returned with ErrCaptchaNeeded
+type CaptchaSolver interface {
+	// SolveCaptcha returns text from captcha image located at img
+	SolveCaptcha(ctx context.Context, img string) (key string, err error)
+}
+
+// CaptchaSolverFunc is adapter to use ordinary function as CaptchaSolver
+type CaptchaSolverFunc func(ctx context.Context, img string) (string, error)
+
+// SolveCaptcha calls f(ctx, img)
+func (f CaptchaSolverFunc) SolveCaptcha(ctx context.Context, img string) (string, error) {
+	return f(ctx, img)
+}
+
+// withCaptcha returns copy of request with captcha answer
+func (r Request) withCaptcha(sid, key string) Request {
+	values := url.Values{}
+	for k, v := range r.Values {
+		values[k] = v
+	}
+	values.Set(paramCaptchaSID, sid)
+	values.Set(paramCaptchaKey, key)
+	r.Values = values
+	return r
+}
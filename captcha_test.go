@@ -0,0 +1,87 @@
+package vk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCaptcha(t *testing.T) {
+	Convey("Captcha", t, func() {
+		const (
+			sid = "548135484327"
+			img = "https://api.vk.com/captcha.php?sid=548135484327"
+			key = "qwerty"
+		)
+		var requests []*http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			if r.URL.Query().Get(paramCaptchaKey) != key {
+				fmt.Fprintf(w, `{"error": {"error_code": 14, "error_msg": "Captcha needed",
+					"captcha_sid": %q, "captcha_img": %q}}`, sid, img)
+				return
+			}
+			w.Write([]byte(`{"response": 1}`))
+		}))
+		defer server.Close()
+
+		var images []string
+		solver := CaptchaSolverFunc(func(ctx context.Context, url string) (string, error) {
+			images = append(images, url)
+			return key, nil
+		})
+		request := Request{Method: "wall.post", Token: "token"}
+		request.Values = map[string][]string{"message": {"hello"}}
+
+		Convey("Error carries captcha", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil))
+			_, err := client.Do(request)
+			So(ErrCaptchaNeeded.Is(err), ShouldBeTrue)
+			e, ok := AsError(err)
+			So(ok, ShouldBeTrue)
+			So(e.CaptchaSID, ShouldEqual, sid)
+			So(e.CaptchaImg, ShouldEqual, img)
+		})
+		Convey("Solved and re-sent", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil), WithCaptchaSolver(solver))
+			res, err := client.Do(request)
+			So(err, ShouldBeNil)
+			So(res.Response.String(), ShouldEqual, "1")
+			So(images, ShouldResemble, []string{img})
+			So(requests, ShouldHaveLength, 2)
+			query := requests[1].URL.Query()
+			So(query.Get(paramCaptchaSID), ShouldEqual, sid)
+			So(query.Get(paramCaptchaKey), ShouldEqual, key)
+			So(query.Get("message"), ShouldEqual, "hello")
+			So(query.Get(paramToken), ShouldEqual, "token")
+			// original request is not modified
+			So(request.Values.Get(paramCaptchaKey), ShouldBeBlank)
+		})
+		Convey("Solver error", func() {
+			solverErr := errors.New("solver failed")
+			client := New(WithBaseURL(server.URL), WithLimiter(nil), WithCaptchaSolver(
+				CaptchaSolverFunc(func(ctx context.Context, url string) (string, error) {
+					return "", solverErr
+				}),
+			))
+			_, err := client.Do(request)
+			So(err, ShouldEqual, solverErr)
+			So(requests, ShouldHaveLength, 1)
+		})
+		Convey("Wrong answers are limited", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil), WithCaptchaSolver(
+				CaptchaSolverFunc(func(ctx context.Context, url string) (string, error) {
+					return "wrong", nil
+				}),
+			))
+			_, err := client.Do(request)
+			So(ErrCaptchaNeeded.Is(err), ShouldBeTrue)
+			So(requests, ShouldHaveLength, maxCaptchaAttempts+1)
+		})
+	})
+}
@@ -0,0 +1,272 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	// dumpRangeSize is count of members requested by single
+	// GetBatch call, that is maxExecuteCalls pages of 1000
+	dumpRangeSize = maxExecuteCalls * 1000
+	// dumpEdgeSize is count of members at both edges of range,
+	// that are saved to checkpoint to be skipped after resume
+	dumpEdgeSize   = 200
+	checkpointMode = 0644
+)
+
+// DumpProgress is state of Dumper reported after every range
+type DumpProgress struct {
+	// Total is members count reported by vk
+	Total int
+	// Members is count of unique members received in this run
+	Members int
+	// Ranges is count of offset ranges, each of dumpRangeSize members
+	Ranges int
+	// Completed is count of completed ranges, including
+	// ranges completed before resume
+	Completed int
+}
+
+// dumpCheckpoint is state of Dumper saved to checkpoint file
+type dumpCheckpoint struct {
+	GroupID   int   `json:"group_id"`
+	RangeSize int   `json:"range_size"`
+	Total     int   `json:"total"`
+	Completed []int `json:"completed"`
+	// Seen are ids of members at edges of completed ranges
+	Seen []int `json:"seen,omitempty"`
+}
+
+// Dumper receives all members of large community, splitting offsets
+// to ranges that are requested by parallel workers with GetBatch.
+// Use client created with NewWithPool to spread requests between
+// tokens. Completed ranges are saved to checkpoint file, so dump can
+// be resumed after failure; checkpoint is removed when dump is done.
+// Members that shift between ranges while dump is running are
+// received only once within single run. After resume only members
+// that shifted by less than dumpEdgeSize positions are skipped,
+// because checkpoint keeps ids of members at edges of ranges only.
+type Dumper struct {
+	groups     Groups
+	groupID    int
+	fields     string
+	workers    int
+	checkpoint string
+	progress   func(DumpProgress)
+}
+
+// NewDumper returns Dumper of members of community
+func NewDumper(groups Groups, groupID int) *Dumper {
+	return &Dumper{
+		groups:  groups,
+		groupID: groupID,
+		workers: 1,
+	}
+}
+
+// SetFields sets user fields that are requested for members
+func (d *Dumper) SetFields(fields string) {
+	d.fields = fields
+}
+
+// SetWorkers sets count of ranges that are requested concurrently
+func (d *Dumper) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	d.workers = workers
+}
+
+// SetCheckpoint sets path of checkpoint file, blank disables checkpoints
+func (d *Dumper) SetCheckpoint(path string) {
+	d.checkpoint = path
+}
+
+// SetProgress sets function that is called after every completed range
+func (d *Dumper) SetProgress(fn func(DumpProgress)) {
+	d.progress = fn
+}
+
+// loadCheckpoint returns saved state or blank state
+// if there is no checkpoint
+func (d *Dumper) loadCheckpoint() (dumpCheckpoint, error) {
+	state := dumpCheckpoint{GroupID: d.groupID, RangeSize: dumpRangeSize}
+	if len(d.checkpoint) == 0 {
+		return state, nil
+	}
+	data, err := ioutil.ReadFile(d.checkpoint)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	var saved dumpCheckpoint
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return state, err
+	}
+	if saved.GroupID != d.groupID || saved.RangeSize != dumpRangeSize {
+		return state, fmt.Errorf("vk: checkpoint %s is for another dump of group %d", d.checkpoint, saved.GroupID)
+	}
+	return saved, nil
+}
+
+func (d *Dumper) saveCheckpoint(state dumpCheckpoint) error {
+	if len(d.checkpoint) == 0 {
+		return nil
+	}
+	sort.Ints(state.Completed)
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(d.checkpoint, data, checkpointMode)
+}
+
+// removeCheckpoint removes checkpoint of finished dump,
+// so next run starts from scratch
+func (d *Dumper) removeCheckpoint() error {
+	if len(d.checkpoint) == 0 {
+		return nil
+	}
+	if err := os.Remove(d.checkpoint); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// edges returns ids of members at both edges of range
+func edges(members []User) []int {
+	var ids []int
+	for i, member := range members {
+		if i < dumpEdgeSize || i >= len(members)-dumpEdgeSize {
+			ids = append(ids, member.ID)
+		}
+	}
+	return ids
+}
+
+// Run receives members and calls fn for every unique member.
+// Calls of fn are serialized, so it does not need to be safe
+// for concurrent use. Error of fn stops dump.
+func (d *Dumper) Run(ctx context.Context, fn func(User) error) error {
+	state, err := d.loadCheckpoint()
+	if err != nil {
+		return err
+	}
+	head, err := d.groups.GetMembersContext(ctx, GroupSearchFields{ID: d.groupID, Count: 1})
+	if err != nil {
+		return err
+	}
+	state.Total = head.Count
+
+	completed := make(map[int]bool, len(state.Completed))
+	for _, offset := range state.Completed {
+		completed[offset] = true
+	}
+	var pending []int
+	for offset := 0; offset < state.Total; offset += dumpRangeSize {
+		if !completed[offset] {
+			pending = append(pending, offset)
+		}
+	}
+	progress := DumpProgress{
+		Total:     state.Total,
+		Ranges:    (state.Total + dumpRangeSize - 1) / dumpRangeSize,
+		Completed: len(state.Completed),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mux      sync.Mutex
+		seen     = make(map[int]bool)
+		firstErr error
+	)
+	for _, id := range state.Seen {
+		seen[id] = true
+	}
+	fail := func(err error) {
+		mux.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mux.Unlock()
+		cancel()
+	}
+	// complete delivers members of range and saves checkpoint
+	complete := func(offset int, members []User) error {
+		mux.Lock()
+		defer mux.Unlock()
+		if firstErr != nil {
+			return firstErr
+		}
+		for _, member := range members {
+			if seen[member.ID] {
+				continue
+			}
+			seen[member.ID] = true
+			if err := fn(member); err != nil {
+				return err
+			}
+			progress.Members++
+		}
+		state.Completed = append(state.Completed, offset)
+		state.Seen = append(state.Seen, edges(members)...)
+		if err := d.saveCheckpoint(state); err != nil {
+			return err
+		}
+		progress.Completed++
+		if d.progress != nil {
+			d.progress(progress)
+		}
+		return nil
+	}
+
+	offsets := make(chan int)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				members, _, err := d.groups.GetBatchContext(ctx, GroupGetFields{
+					GroupID: d.groupID,
+					Offset:  offset,
+					Fields:  d.fields,
+				})
+				if err == nil {
+					err = complete(offset, members)
+				}
+				if err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+feed:
+	for _, offset := range pending {
+		select {
+		case offsets <- offset:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(offsets)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	return d.removeCheckpoint()
+}
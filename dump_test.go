@@ -0,0 +1,185 @@
+package vk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var batchOffsetRegexp = regexp.MustCompile(`var offset = (\d+);`)
+
+// membersMock serves members with ids from 1 to total,
+// every range after first also repeats last member of
+// previous range, as if member shifted between pages.
+// Members are ids if no fields are requested, like in vk.
+type membersMock struct {
+	total int
+
+	mux     sync.Mutex
+	offsets []int
+	fail    map[int]error
+}
+
+func (m *membersMock) Do(req Request) (*Response, error) {
+	return m.DoContext(context.Background(), req)
+}
+
+func (m *membersMock) DoContext(ctx context.Context, req Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Method == methodGroupsGetMembers {
+		return newApiMock(fmt.Sprintf(`{"response": {"count": %d, "items": [1]}}`, m.total), nil).DoContext(ctx, req)
+	}
+	offset, _ := strconv.Atoi(batchOffsetRegexp.FindStringSubmatch(req.Values.Get("code"))[1])
+	m.mux.Lock()
+	m.offsets = append(m.offsets, offset)
+	err := m.fail[offset]
+	m.mux.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	member := `{"id": %d}`
+	if strings.Contains(req.Values.Get("code"), `"fields":""`) {
+		member = `%d`
+	}
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, `{"response": {"count": %d, "members": [`, m.total)
+	first := offset + 1
+	if offset > 0 {
+		first--
+	}
+	for id := first; id <= offset+dumpRangeSize && id <= m.total; id++ {
+		if id != first {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(b, member, id)
+	}
+	b.WriteString("]}}")
+	return newApiMock(b.String(), nil).DoContext(ctx, req)
+}
+
+func TestDumper(t *testing.T) {
+	Convey("Dumper", t, func() {
+		dir, err := ioutil.TempDir("", "vk-dump")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		checkpoint := filepath.Join(dir, "checkpoint.json")
+
+		total := dumpRangeSize*3 + 10
+		mock := &membersMock{total: total, fail: make(map[int]error)}
+		groups := Groups{record(mock, DefaultFactory)}
+		dumper := NewDumper(groups, 1)
+		dumper.SetWorkers(3)
+		dumper.SetCheckpoint(checkpoint)
+		var reports []DumpProgress
+		dumper.SetProgress(func(p DumpProgress) {
+			reports = append(reports, p)
+		})
+		seen := make(map[int]int)
+		collect := func(u User) error {
+			seen[u.ID]++
+			return nil
+		}
+
+		Convey("All members", func() {
+			So(dumper.Run(context.Background(), collect), ShouldBeNil)
+			So(seen, ShouldHaveLength, total)
+			for _, n := range seen {
+				So(n, ShouldEqual, 1)
+			}
+			So(mock.offsets, ShouldHaveLength, 4)
+			So(reports, ShouldHaveLength, 4)
+			last := reports[3]
+			So(last.Total, ShouldEqual, total)
+			So(last.Members, ShouldEqual, total)
+			So(last.Ranges, ShouldEqual, 4)
+			So(last.Completed, ShouldEqual, 4)
+
+			_, err = os.Stat(checkpoint)
+			So(os.IsNotExist(err), ShouldBeTrue)
+
+			Convey("Finished dump is repeated", func() {
+				reports = nil
+				So(dumper.Run(context.Background(), collect), ShouldBeNil)
+				So(mock.offsets, ShouldHaveLength, 8)
+				So(reports, ShouldHaveLength, 4)
+				for _, n := range seen {
+					So(n, ShouldEqual, 2)
+				}
+			})
+		})
+		Convey("Fields", func() {
+			dumper.SetFields("sex")
+			So(dumper.Run(context.Background(), collect), ShouldBeNil)
+			So(seen, ShouldHaveLength, total)
+		})
+		Convey("Resume", func() {
+			failure := errors.New("failure")
+			mock.fail[dumpRangeSize*2] = failure
+			dumper.SetWorkers(1)
+			So(dumper.Run(context.Background(), collect), ShouldEqual, failure)
+			So(len(seen), ShouldEqual, dumpRangeSize*2)
+
+			data, err := ioutil.ReadFile(checkpoint)
+			So(err, ShouldBeNil)
+			var state dumpCheckpoint
+			So(json.Unmarshal(data, &state), ShouldBeNil)
+			So(state.Completed, ShouldResemble, []int{0, dumpRangeSize})
+			So(state.Seen, ShouldHaveLength, dumpEdgeSize*4)
+
+			// new dumper, as if dump is resumed by another process
+			delete(mock.fail, dumpRangeSize*2)
+			mock.offsets = nil
+			reports = nil
+			dumper := NewDumper(groups, 1)
+			dumper.SetCheckpoint(checkpoint)
+			dumper.SetProgress(func(p DumpProgress) {
+				reports = append(reports, p)
+			})
+			So(dumper.Run(context.Background(), collect), ShouldBeNil)
+			So(mock.offsets, ShouldResemble, []int{dumpRangeSize * 2, dumpRangeSize * 3})
+			So(seen, ShouldHaveLength, total)
+			for _, n := range seen {
+				So(n, ShouldEqual, 1)
+			}
+			So(reports[len(reports)-1].Completed, ShouldEqual, 4)
+			So(reports[len(reports)-1].Members, ShouldEqual, total-dumpRangeSize*2)
+		})
+		Convey("Callback error", func() {
+			failure := errors.New("failure")
+			err := dumper.Run(context.Background(), func(u User) error {
+				return failure
+			})
+			So(err, ShouldEqual, failure)
+			So(reports, ShouldBeEmpty)
+			_, err = os.Stat(checkpoint)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+		Convey("Cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			So(dumper.Run(ctx, collect), ShouldEqual, context.Canceled)
+		})
+		Convey("Another group", func() {
+			mock.fail[dumpRangeSize] = errors.New("failure")
+			dumper.SetWorkers(1)
+			So(dumper.Run(context.Background(), collect), ShouldNotBeNil)
+			other := NewDumper(groups, 2)
+			other.SetCheckpoint(checkpoint)
+			So(other.Run(context.Background(), collect), ShouldNotBeNil)
+		})
+	})
+}
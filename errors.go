@@ -1,7 +1,9 @@
 package vk
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 )
 
 //go:generate stringer -type=ServerError
@@ -23,6 +25,10 @@ type Error struct {
 	Message string         `json:"error_msg,omitempty"`
 	Params  []RequestParam `json:"request_params,omitempty"`
 	Request Request        `json:"-"`
+
+	// CaptchaSID and CaptchaImg are set for ErrCaptchaNeeded
+	CaptchaSID string `json:"captcha_sid,omitempty"`
+	CaptchaImg string `json:"captcha_img,omitempty"`
 }
 
 func (e *Error) setRequest(r Request) {
@@ -37,34 +43,120 @@ func (e ExecuteError) Error() string {
 	return fmt.Sprintf("%s: %s (%d)", e.Method, e.Message, e.Code)
 }
 
-// Is returns true, if err equals (or is Error with code equals) e
+// Is returns true, if target is ServerError, Error
+// or ExecuteError with same code,
+// so errors.Is(err, ErrAuthFailed) matches Error with that code
+func (e Error) Is(target error) bool {
+	return e.Code.Is(target)
+}
+
+// Is returns true, if target is ServerError, Error
+// or ExecuteError with same code
+func (e ExecuteError) Is(target error) bool {
+	return e.Code.Is(target)
+}
+
+// Is returns true, if err equals (or is Error with code equals) e.
+// It is symmetric, so both e.Is(err) and errors.Is(err, e) can be used.
 func (e ServerError) Is(err error) bool {
 	if error(e) == err {
 		return true
 	}
-	if another, ok := err.(ServerError); ok {
+	switch another := err.(type) {
+	case ServerError:
 		return another == e
-	}
-	if another, ok := err.(Error); ok {
+	case Error:
+		return another.Code == e
+	case ExecuteError:
 		return another.Code == e
 	}
 	return false
 }
 
+// IsServerError returns true if err is or wraps Error
 func IsServerError(err error) bool {
-	if _, ok := err.(Error); ok {
-		return true
+	_, ok := AsError(err)
+	return ok
+}
+
+// AsError returns first Error in err chain
+func AsError(err error) (Error, bool) {
+	var s Error
+	if errors.As(err, &s) {
+		return s, true
 	}
-	return false
+	return s, false
 }
 
+// GetServerError returns Error from err chain, panics if there is none.
+//
+// Deprecated: use AsError, that reports absence of Error instead of panic.
 func GetServerError(err error) Error {
-	if s, ok := err.(Error); ok {
+	if s, ok := AsError(err); ok {
 		return s
 	}
 	panic("not a server error")
 }
 
+// RequestError is failure of request that has no vk error,
+// e.g. transport error or bad http status
+type RequestError struct {
+	Method string
+	// StatusCode of http response, zero on transport error
+	StatusCode int
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: http %d: %v", e.Method, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Method, e.Err)
+}
+
+// Unwrap returns underlying error
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// newRequestError wraps err with request context,
+// access token is removed from url of transport errors
+func newRequestError(request Request, a Attempt, err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		redactedErr := *urlErr
+		redactedErr.URL = redact(urlErr.URL, request.Token)
+		err = &redactedErr
+	}
+	return &RequestError{Method: request.Method, StatusCode: a.StatusCode, Err: err}
+}
+
+// PermissionError is failure of community method, because token owner
+// has no rights in community, e.g. is not its manager
+type PermissionError struct {
+	Method  string
+	GroupID int
+	Err     error
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("%s: no permission in group %d: %v", e.Method, e.GroupID, e.Err)
+}
+
+// Unwrap returns underlying error
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// groupPermissionError wraps access errors of community method
+// with PermissionError, other errors are returned as is
+func groupPermissionError(method string, groupID int, err error) error {
+	code, ok := codeOf(err)
+	if !ok || (code != ErrNotAllowed && code != ErrGroupAccessProhibited) {
+		return err
+	}
+	return &PermissionError{Method: method, GroupID: groupID, Err: err}
+}
+
 type ErrorResponse struct {
 	Error Error `json:"error"`
 }
@@ -76,36 +168,272 @@ func (s ServerError) Error() string {
 const (
 	// Possible error codes
 	// https://vk.com/dev/errors
-	ErrZero ServerError = iota
-	ErrUnknown
-	ErrApplicationDisabled
-	ErrUnknownMethod
-	ErrInvalidSignature
-	ErrAuthFailed
-	ErrTooManyRequests
-	ErrInsufficientPermissions
-	ErrInvalidRequest
-	ErrTooManyOneTypeRequests
-	ErrInternalServerError
-	ErrAppInTestMode
-	ErrCaptchaNeeded
-	ErrNotAllowed
-	ErrHttpsOnly
-	ErrNeedValidation
-	ErrStandaloneOnly
-	ErrStandaloneOpenAPIOnly
-	ErrMethodDisabled
-	ErrNeedConfirmation
-	ErrOneOfParametersInvalid    ServerError = 100
-	ErrInvalidAPIID              ServerError = 101
-	ErrInvalidAUserID            ServerError = 113
-	ErrInvalidTimestamp          ServerError = 150
-	ErrAlbumAccessProhibited     ServerError = 200
-	ErrGroupAccessProhibited     ServerError = 203
-	ErrAlbumOverflow             ServerError = 300
-	ErrMoneyTransferNotAllowed   ServerError = 500
+	ErrZero                ServerError = 0
+	ErrUnknown             ServerError = 1 // unknown error occurred
+	ErrApplicationDisabled ServerError = 2 // application is disabled
+	ErrUnknownMethod       ServerError = 3 // unknown method passed
+	ErrInvalidSignature    ServerError = 4 // incorrect signature
+	ErrAuthFailed          ServerError = 5 // user authorization failed
+	ErrTooManyRequests     ServerError = 6 // too many requests per second
+	// permission to perform this action is denied
+	ErrInsufficientPermissions ServerError = 7
+	ErrInvalidRequest          ServerError = 8  // invalid request
+	ErrTooManyOneTypeRequests  ServerError = 9  // flood control
+	ErrInternalServerError     ServerError = 10 // internal server error
+	// in test mode application should be disabled or user should be authorized
+	ErrAppInTestMode  ServerError = 11
+	ErrCaptchaNeeded  ServerError = 14 // captcha needed
+	ErrNotAllowed     ServerError = 15 // access denied
+	ErrHttpsOnly      ServerError = 16 // http authorization failed
+	ErrNeedValidation ServerError = 17 // validation required
+	ErrUserDeleted    ServerError = 18 // user was deleted or banned
+	// permission to perform this action is denied for non-standalone applications
+	ErrStandaloneOnly ServerError = 20
+	// permission to perform this action is allowed only for standalone and OpenAPI applications
+	ErrStandaloneOpenAPIOnly    ServerError = 21
+	ErrMethodDisabled           ServerError = 23 // this method was disabled
+	ErrNeedConfirmation         ServerError = 24 // confirmation required
+	ErrGroupAuthFailed          ServerError = 27 // group authorization failed
+	ErrAppAuthFailed            ServerError = 28 // application authorization failed
+	ErrRateLimitReached         ServerError = 29 // rate limit of method reached
+	ErrPrivateProfile           ServerError = 30 // this profile is private
+	ErrNotImplemented           ServerError = 33 // not implemented yet
+	ErrUserBanned               ServerError = 37 // user was banned
+	ErrUnknownApplication       ServerError = 38 // unknown application
+	ErrUnknownUser              ServerError = 39 // unknown user
+	ErrUnknownGroup             ServerError = 40 // unknown group
+	ErrAdditionalSignupRequired ServerError = 41 // additional signup required
+
+	// one of the parameters specified was missing or invalid
+	ErrOneOfParametersInvalid ServerError = 100
+	ErrInvalidAPIID           ServerError = 101 // invalid application api id
+	ErrOutOfLimits            ServerError = 103 // out of limits
+	ErrNotFound               ServerError = 104 // not found
+	ErrCouldNotSaveFile       ServerError = 105 // couldn't save file
+	ErrUnableToProcessAction  ServerError = 106 // unable to process action
+	ErrInvalidAUserID         ServerError = 113 // invalid user id
+	ErrInvalidAlbumID         ServerError = 114 // invalid album id
+	ErrInvalidServer          ServerError = 118 // invalid server
+	ErrInvalidHash            ServerError = 121 // invalid hash
+	ErrInvalidGroupID         ServerError = 125 // invalid group id
+	ErrInvalidPhoto           ServerError = 129 // invalid photo
+	ErrPageNotFound           ServerError = 140 // page not found
+	ErrPageAccessDenied       ServerError = 141 // access to page denied
+	ErrMobileNumberUnknown    ServerError = 146 // mobile number of the user is unknown
+	ErrInsufficientFunds      ServerError = 147 // application has insufficient funds
+	ErrMenuAccessDenied       ServerError = 148 // access to the menu of the user denied
+	ErrInvalidTimestamp       ServerError = 150 // invalid timestamp
+	ErrInvalidListID          ServerError = 171 // invalid list id
+	ErrTooManyLists           ServerError = 173 // reached the maximum number of lists
+	ErrCannotAddSelfAsFriend  ServerError = 174 // cannot add user himself as friend
+	// cannot add this user to friends as they have put you on their blacklist
+	ErrBlacklistedByUser ServerError = 175
+	// cannot add this user to friends as you put them on blacklist
+	ErrUserBlacklisted     ServerError = 176
+	ErrFriendNotFound      ServerError = 177 // cannot add this user to friends as user not found
+	ErrNoteNotFound        ServerError = 180 // note not found
+	ErrNoteAccessDenied    ServerError = 181 // access to note denied
+	ErrNoteCommentsClosed  ServerError = 182 // you can't comment this note
+	ErrCommentAccessDenied ServerError = 183 // access to comment denied
+
+	ErrAlbumAccessProhibited     ServerError = 200 // access to album denied
+	ErrAudioAccessProhibited     ServerError = 201 // access to audio denied
+	ErrGroupAccessProhibited     ServerError = 203 // access to group denied
+	ErrObjectAccessDenied        ServerError = 204 // access denied
+	ErrWallPostAccessDenied      ServerError = 210 // access to wall's post denied
+	ErrWallCommentAccessDenied   ServerError = 211 // access to wall's comment denied
+	ErrPostCommentsAccessDenied  ServerError = 212 // access to post comments denied
+	ErrStatusRepliesAccessDenied ServerError = 213 // access to status replies denied
+	ErrPostAddAccessDenied       ServerError = 214 // access to adding post denied
+	ErrAdPostRecentlyAdded       ServerError = 219 // advertisement post was recently added
+	ErrTooManyRecipients         ServerError = 220 // too many recipients
+	ErrHyperlinksForbidden       ServerError = 222 // hyperlinks are forbidden
+	ErrTooManyReplies            ServerError = 223 // too many replies
+	ErrTooManyAdPosts            ServerError = 224 // too many ads posts
+	ErrDonutDisabled             ServerError = 225 // donut is disabled
+	ErrPollAccessDenied          ServerError = 250 // access to poll denied
+	ErrInvalidPollID             ServerError = 251 // invalid poll id
+	ErrInvalidPollAnswerID       ServerError = 252 // invalid answer id
+	ErrPollVoteRequired          ServerError = 253 // access denied, please vote first
+	// access to the groups list is denied due to the user's privacy settings
+	ErrGroupsListAccessDenied ServerError = 260
+	ErrAlbumOverflow          ServerError = 300 // this album is full
+
+	// permission denied, you must enable votes processing in application settings
+	ErrMoneyTransferNotAllowed ServerError = 500
+	ErrNotEnoughVotes          ServerError = 503 // not enough votes
+	// permission denied, you have no access to operations specified with given object(s)
 	ErrInsufficientPermissionsAd ServerError = 600
-	ErrInternalServerErrorAd     ServerError = 603
+	// permission denied, you have requested too many actions this day
+	ErrTooManyAdsActions     ServerError = 601
+	ErrInternalServerErrorAd ServerError = 603 // some ads error occurred
+
+	ErrCannotEditCreatorRole      ServerError = 700 // cannot edit creator role
+	ErrUserNotInClub              ServerError = 701 // user should be in club
+	ErrTooManyOfficers            ServerError = 702 // too many officers in club
+	ErrNeed2FA                    ServerError = 703 // you need to enable 2FA for this action
+	ErrUserNeed2FA                ServerError = 704 // user needs to enable 2FA for this action
+	ErrAppNotInstalledInCommunity ServerError = 711 // application is not installed in community
+	ErrVideoAlreadyAdded          ServerError = 800 // this video is already added
+	ErrVideoCommentsClosed        ServerError = 801 // comments for this video are closed
+
+	ErrMessagesBlacklisted ServerError = 900 // can't send messages for users from blacklist
+	// can't send messages for users without permission
+	ErrMessagesForbidden ServerError = 901
+	// can't send messages to this user due to their privacy settings
+	ErrMessagesPrivacy           ServerError = 902
+	ErrMessageTooOld             ServerError = 909 // can't edit this message, because it's too old
+	ErrMessageTooBig             ServerError = 910 // can't send this message, because it's too big
+	ErrInvalidKeyboard           ServerError = 911 // keyboard format is invalid
+	ErrChatBotFeature            ServerError = 912 // this is a chat bot feature
+	ErrTooManyForwarded          ServerError = 913 // too many forwarded messages
+	ErrMessageTooLong            ServerError = 914 // message is too long
+	ErrChatAccessDenied          ServerError = 917 // you don't have access to this chat
+	ErrCannotForward             ServerError = 921 // can't forward these messages
+	ErrNotChatAdmin              ServerError = 925 // you are not admin of this chat
+	ErrPeerInteractionForbidden  ServerError = 932 // your community can't interact with this peer
+	ErrContactNotFound           ServerError = 936 // contact not found
+	ErrMessageRequestAlreadySent ServerError = 939 // message request already sent
+	ErrTooManyPosts              ServerError = 940 // too many posts in messages
+	ErrTooManyPinned             ServerError = 942 // too many pinned messages
+	ErrChatDisabled              ServerError = 945 // chat was disabled
+	ErrChatNotSupported          ServerError = 946 // chat not supported
+
+	ErrInvalidDocumentID            ServerError = 1150 // invalid document id
+	ErrDocumentDeleteDenied         ServerError = 1151 // access to document deleting is denied
+	ErrInvalidDocumentTitle         ServerError = 1152 // invalid document title
+	ErrDocumentAccessDenied         ServerError = 1153 // access to document is denied
+	ErrOriginalPhotoChanged         ServerError = 1160 // original photo was changed
+	ErrTooManyFeedLists             ServerError = 1170 // too many feed lists
+	ErrInvalidScreenName            ServerError = 1260 // invalid screen name
+	ErrCatalogUnavailable           ServerError = 1310 // catalog is not available for this user
+	ErrCatalogCategoriesUnavailable ServerError = 1311 // catalog categories are not available for this user
 
 	ErrBadResponseCode ServerError = -1
 )
+
+// errorClass is bit set of error classes
+type errorClass uint8
+
+const (
+	classRetryable errorClass = 1 << iota
+	classAuth
+	classPermission
+	classRateLimit
+)
+
+// errorClasses classifies error codes, codes
+// that are not listed belong to no class
+var errorClasses = map[ServerError]errorClass{
+	ErrUnknown:                classRetryable,
+	ErrTooManyRequests:        classRetryable | classRateLimit,
+	ErrTooManyOneTypeRequests: classRetryable | classRateLimit,
+	ErrInternalServerError:    classRetryable,
+	ErrRateLimitReached:       classRateLimit,
+	ErrTooManyAdsActions:      classRateLimit,
+
+	ErrAuthFailed:      classAuth,
+	ErrHttpsOnly:       classAuth,
+	ErrNeedValidation:  classAuth,
+	ErrGroupAuthFailed: classAuth,
+	ErrAppAuthFailed:   classAuth,
+
+	ErrInsufficientPermissions:   classPermission,
+	ErrNotAllowed:                classPermission,
+	ErrUserDeleted:               classPermission,
+	ErrStandaloneOnly:            classPermission,
+	ErrStandaloneOpenAPIOnly:     classPermission,
+	ErrPrivateProfile:            classPermission,
+	ErrUserBanned:                classPermission,
+	ErrPageAccessDenied:          classPermission,
+	ErrMenuAccessDenied:          classPermission,
+	ErrNoteAccessDenied:          classPermission,
+	ErrCommentAccessDenied:       classPermission,
+	ErrAlbumAccessProhibited:     classPermission,
+	ErrAudioAccessProhibited:     classPermission,
+	ErrGroupAccessProhibited:     classPermission,
+	ErrObjectAccessDenied:        classPermission,
+	ErrWallPostAccessDenied:      classPermission,
+	ErrWallCommentAccessDenied:   classPermission,
+	ErrPostCommentsAccessDenied:  classPermission,
+	ErrStatusRepliesAccessDenied: classPermission,
+	ErrPostAddAccessDenied:       classPermission,
+	ErrPollAccessDenied:          classPermission,
+	ErrGroupsListAccessDenied:    classPermission,
+	ErrMoneyTransferNotAllowed:   classPermission,
+	ErrInsufficientPermissionsAd: classPermission,
+	ErrMessagesBlacklisted:       classPermission,
+	ErrMessagesForbidden:         classPermission,
+	ErrMessagesPrivacy:           classPermission,
+	ErrChatAccessDenied:          classPermission,
+	ErrDocumentDeleteDenied:      classPermission,
+	ErrDocumentAccessDenied:      classPermission,
+}
+
+func (e ServerError) is(class errorClass) bool {
+	return errorClasses[e]&class != 0
+}
+
+// Retryable returns true if request failed with e can succeed later
+func (e ServerError) Retryable() bool { return e.is(classRetryable) }
+
+// codeOf returns vk error code from err chain
+func codeOf(err error) (ServerError, bool) {
+	var (
+		e       Error
+		execErr ExecuteError
+		code    ServerError
+	)
+	switch {
+	case errors.As(err, &e):
+		return e.Code, true
+	case errors.As(err, &execErr):
+		return execErr.Code, true
+	case errors.As(err, &code):
+		return code, true
+	}
+	return ErrZero, false
+}
+
+// attempt returns failed attempt that resulted in e
+func (e *RequestError) attempt() Attempt {
+	a := Attempt{StatusCode: e.StatusCode}
+	if code, ok := codeOf(e.Err); ok {
+		a.Code = code
+	} else {
+		a.Err = e.Err
+	}
+	return a
+}
+
+// IsRetryable returns true if err is temporary vk error, bad gateway
+// or rate limiting http status, or transport error, that is error
+// that is repeated by Backoff policy
+func IsRetryable(err error) bool {
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		return isTemporary(requestErr.attempt())
+	}
+	code, ok := codeOf(err)
+	return ok && code.is(classRetryable)
+}
+
+// IsAuthError returns true if err is caused by invalid access token
+// or application, so request will not succeed without authorization
+func IsAuthError(err error) bool {
+	code, ok := codeOf(err)
+	return ok && code.is(classAuth)
+}
+
+// IsPermissionError returns true if err is caused by lack
+// of access rights to object or method
+func IsPermissionError(err error) bool {
+	code, ok := codeOf(err)
+	return ok && code.is(classPermission)
+}
+
+// IsRateLimit returns true if err is caused by request rate limits
+func IsRateLimit(err error) bool {
+	code, ok := codeOf(err)
+	return ok && code.is(classRateLimit)
+}
@@ -1,8 +1,12 @@
 package vk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -13,6 +17,8 @@ func TestErrors(t *testing.T) {
 		Convey("Recognition", func() {
 			So(IsServerError(io.ErrUnexpectedEOF), ShouldBeFalse)
 			So(func() { GetServerError(io.ErrUnexpectedEOF) }, ShouldPanic)
+			_, ok := AsError(io.ErrUnexpectedEOF)
+			So(ok, ShouldBeFalse)
 		})
 		Convey("Equality", func() {
 			So(ErrZero.Is(ErrZero), ShouldBeTrue)
@@ -45,3 +51,212 @@ func TestErrors(t *testing.T) {
 		})
 	})
 }
+
+func TestErrorsIntegration(t *testing.T) {
+	Convey("Standard errors integration", t, func() {
+		Convey("Is through wrapping", func() {
+			err := fmt.Errorf("crawl: %w", Error{Code: ErrAuthFailed, Message: "User authorization failed"})
+			So(errors.Is(err, ErrAuthFailed), ShouldBeTrue)
+			So(errors.Is(err, ErrTooManyRequests), ShouldBeFalse)
+			So(errors.Is(err, Error{Code: ErrAuthFailed}), ShouldBeTrue)
+			So(IsServerError(err), ShouldBeTrue)
+			e, ok := AsError(err)
+			So(ok, ShouldBeTrue)
+			So(e.Code, ShouldEqual, ErrAuthFailed)
+		})
+		Convey("As", func() {
+			err := fmt.Errorf("crawl: %w", Error{Code: ErrCaptchaNeeded, CaptchaSID: "1"})
+			var e Error
+			So(errors.As(err, &e), ShouldBeTrue)
+			So(e.CaptchaSID, ShouldEqual, "1")
+			_, ok := AsError(io.EOF)
+			So(ok, ShouldBeFalse)
+		})
+		Convey("Execute errors", func() {
+			var err error = Errors{
+				{Method: "users.get", Code: ErrNotAllowed},
+				{Method: "groups.get", Code: ErrGroupAccessProhibited},
+			}
+			So(errors.Is(err, ErrNotAllowed), ShouldBeTrue)
+			So(errors.Is(err, ErrGroupAccessProhibited), ShouldBeTrue)
+			So(errors.Is(err, ErrAuthFailed), ShouldBeFalse)
+			var e ExecuteError
+			So(errors.As(err, &e), ShouldBeTrue)
+			So(e.Method, ShouldEqual, "users.get")
+			So(IsServerError(err), ShouldBeFalse)
+		})
+		Convey("Request error", func() {
+			err := newRequestError(
+				Request{Method: "users.get", Token: "secret"},
+				Attempt{Err: io.EOF},
+				&url.Error{Op: "Get", URL: "https://api.vk.com/method/users.get?access_token=secret", Err: io.EOF},
+			)
+			So(errors.Is(err, io.EOF), ShouldBeTrue)
+			So(err.Error(), ShouldStartWith, "users.get: ")
+			So(err.Error(), ShouldNotContainSubstring, "secret")
+			var urlErr *url.Error
+			So(errors.As(err, &urlErr), ShouldBeTrue)
+		})
+	})
+}
+
+func TestServerErrorCatalogue(t *testing.T) {
+	Convey("Error codes", t, func() {
+		for _, v := range []struct {
+			err        ServerError
+			code       int
+			retryable  bool
+			auth       bool
+			permission bool
+			rateLimit  bool
+		}{
+			{ErrZero, 0, false, false, false, false},
+			{ErrUnknown, 1, true, false, false, false},
+			{ErrApplicationDisabled, 2, false, false, false, false},
+			{ErrUnknownMethod, 3, false, false, false, false},
+			{ErrInvalidSignature, 4, false, false, false, false},
+			{ErrAuthFailed, 5, false, true, false, false},
+			{ErrTooManyRequests, 6, true, false, false, true},
+			{ErrInsufficientPermissions, 7, false, false, true, false},
+			{ErrInvalidRequest, 8, false, false, false, false},
+			{ErrTooManyOneTypeRequests, 9, true, false, false, true},
+			{ErrInternalServerError, 10, true, false, false, false},
+			{ErrAppInTestMode, 11, false, false, false, false},
+			{ErrCaptchaNeeded, 14, false, false, false, false},
+			{ErrNotAllowed, 15, false, false, true, false},
+			{ErrHttpsOnly, 16, false, true, false, false},
+			{ErrNeedValidation, 17, false, true, false, false},
+			{ErrUserDeleted, 18, false, false, true, false},
+			{ErrStandaloneOnly, 20, false, false, true, false},
+			{ErrStandaloneOpenAPIOnly, 21, false, false, true, false},
+			{ErrMethodDisabled, 23, false, false, false, false},
+			{ErrNeedConfirmation, 24, false, false, false, false},
+			{ErrGroupAuthFailed, 27, false, true, false, false},
+			{ErrAppAuthFailed, 28, false, true, false, false},
+			{ErrRateLimitReached, 29, false, false, false, true},
+			{ErrPrivateProfile, 30, false, false, true, false},
+			{ErrNotImplemented, 33, false, false, false, false},
+			{ErrUserBanned, 37, false, false, true, false},
+			{ErrUnknownApplication, 38, false, false, false, false},
+			{ErrUnknownUser, 39, false, false, false, false},
+			{ErrUnknownGroup, 40, false, false, false, false},
+			{ErrAdditionalSignupRequired, 41, false, false, false, false},
+			{ErrOneOfParametersInvalid, 100, false, false, false, false},
+			{ErrInvalidAPIID, 101, false, false, false, false},
+			{ErrOutOfLimits, 103, false, false, false, false},
+			{ErrNotFound, 104, false, false, false, false},
+			{ErrCouldNotSaveFile, 105, false, false, false, false},
+			{ErrUnableToProcessAction, 106, false, false, false, false},
+			{ErrInvalidAUserID, 113, false, false, false, false},
+			{ErrInvalidAlbumID, 114, false, false, false, false},
+			{ErrInvalidServer, 118, false, false, false, false},
+			{ErrInvalidHash, 121, false, false, false, false},
+			{ErrInvalidGroupID, 125, false, false, false, false},
+			{ErrInvalidPhoto, 129, false, false, false, false},
+			{ErrPageNotFound, 140, false, false, false, false},
+			{ErrPageAccessDenied, 141, false, false, true, false},
+			{ErrMobileNumberUnknown, 146, false, false, false, false},
+			{ErrInsufficientFunds, 147, false, false, false, false},
+			{ErrMenuAccessDenied, 148, false, false, true, false},
+			{ErrInvalidTimestamp, 150, false, false, false, false},
+			{ErrInvalidListID, 171, false, false, false, false},
+			{ErrTooManyLists, 173, false, false, false, false},
+			{ErrCannotAddSelfAsFriend, 174, false, false, false, false},
+			{ErrBlacklistedByUser, 175, false, false, false, false},
+			{ErrUserBlacklisted, 176, false, false, false, false},
+			{ErrFriendNotFound, 177, false, false, false, false},
+			{ErrNoteNotFound, 180, false, false, false, false},
+			{ErrNoteAccessDenied, 181, false, false, true, false},
+			{ErrNoteCommentsClosed, 182, false, false, false, false},
+			{ErrCommentAccessDenied, 183, false, false, true, false},
+			{ErrAlbumAccessProhibited, 200, false, false, true, false},
+			{ErrAudioAccessProhibited, 201, false, false, true, false},
+			{ErrGroupAccessProhibited, 203, false, false, true, false},
+			{ErrObjectAccessDenied, 204, false, false, true, false},
+			{ErrWallPostAccessDenied, 210, false, false, true, false},
+			{ErrWallCommentAccessDenied, 211, false, false, true, false},
+			{ErrPostCommentsAccessDenied, 212, false, false, true, false},
+			{ErrStatusRepliesAccessDenied, 213, false, false, true, false},
+			{ErrPostAddAccessDenied, 214, false, false, true, false},
+			{ErrAdPostRecentlyAdded, 219, false, false, false, false},
+			{ErrTooManyRecipients, 220, false, false, false, false},
+			{ErrHyperlinksForbidden, 222, false, false, false, false},
+			{ErrTooManyReplies, 223, false, false, false, false},
+			{ErrTooManyAdPosts, 224, false, false, false, false},
+			{ErrDonutDisabled, 225, false, false, false, false},
+			{ErrPollAccessDenied, 250, false, false, true, false},
+			{ErrInvalidPollID, 251, false, false, false, false},
+			{ErrInvalidPollAnswerID, 252, false, false, false, false},
+			{ErrPollVoteRequired, 253, false, false, false, false},
+			{ErrGroupsListAccessDenied, 260, false, false, true, false},
+			{ErrAlbumOverflow, 300, false, false, false, false},
+			{ErrMoneyTransferNotAllowed, 500, false, false, true, false},
+			{ErrNotEnoughVotes, 503, false, false, false, false},
+			{ErrInsufficientPermissionsAd, 600, false, false, true, false},
+			{ErrTooManyAdsActions, 601, false, false, false, true},
+			{ErrInternalServerErrorAd, 603, false, false, false, false},
+			{ErrCannotEditCreatorRole, 700, false, false, false, false},
+			{ErrUserNotInClub, 701, false, false, false, false},
+			{ErrTooManyOfficers, 702, false, false, false, false},
+			{ErrNeed2FA, 703, false, false, false, false},
+			{ErrUserNeed2FA, 704, false, false, false, false},
+			{ErrAppNotInstalledInCommunity, 711, false, false, false, false},
+			{ErrVideoAlreadyAdded, 800, false, false, false, false},
+			{ErrVideoCommentsClosed, 801, false, false, false, false},
+			{ErrMessagesBlacklisted, 900, false, false, true, false},
+			{ErrMessagesForbidden, 901, false, false, true, false},
+			{ErrMessagesPrivacy, 902, false, false, true, false},
+			{ErrMessageTooOld, 909, false, false, false, false},
+			{ErrMessageTooBig, 910, false, false, false, false},
+			{ErrInvalidKeyboard, 911, false, false, false, false},
+			{ErrChatBotFeature, 912, false, false, false, false},
+			{ErrTooManyForwarded, 913, false, false, false, false},
+			{ErrMessageTooLong, 914, false, false, false, false},
+			{ErrChatAccessDenied, 917, false, false, true, false},
+			{ErrCannotForward, 921, false, false, false, false},
+			{ErrNotChatAdmin, 925, false, false, false, false},
+			{ErrPeerInteractionForbidden, 932, false, false, false, false},
+			{ErrContactNotFound, 936, false, false, false, false},
+			{ErrMessageRequestAlreadySent, 939, false, false, false, false},
+			{ErrTooManyPosts, 940, false, false, false, false},
+			{ErrTooManyPinned, 942, false, false, false, false},
+			{ErrChatDisabled, 945, false, false, false, false},
+			{ErrChatNotSupported, 946, false, false, false, false},
+			{ErrInvalidDocumentID, 1150, false, false, false, false},
+			{ErrDocumentDeleteDenied, 1151, false, false, true, false},
+			{ErrInvalidDocumentTitle, 1152, false, false, false, false},
+			{ErrDocumentAccessDenied, 1153, false, false, true, false},
+			{ErrOriginalPhotoChanged, 1160, false, false, false, false},
+			{ErrTooManyFeedLists, 1170, false, false, false, false},
+			{ErrInvalidScreenName, 1260, false, false, false, false},
+			{ErrCatalogUnavailable, 1310, false, false, false, false},
+			{ErrCatalogCategoriesUnavailable, 1311, false, false, false, false},
+		} {
+			So(int(v.err), ShouldEqual, v.code)
+			So(v.err.String(), ShouldStartWith, "Err")
+			So(v.err.Retryable(), ShouldEqual, v.retryable)
+
+			err := fmt.Errorf("wrapped: %w", Error{Code: v.err})
+			So(IsRetryable(err), ShouldEqual, v.retryable)
+			So(IsAuthError(err), ShouldEqual, v.auth)
+			So(IsPermissionError(err), ShouldEqual, v.permission)
+			So(IsRateLimit(err), ShouldEqual, v.rateLimit)
+
+			var execErr error = Errors{{Method: "users.get", Code: v.err}}
+			So(IsPermissionError(execErr), ShouldEqual, v.permission)
+			So(IsRateLimit(v.err), ShouldEqual, v.rateLimit)
+		}
+	})
+	Convey("Request errors", t, func() {
+		So(IsRetryable(&RequestError{Method: "users.get", Err: io.EOF}), ShouldBeTrue)
+		So(IsRetryable(&RequestError{Method: "users.get", StatusCode: 502, Err: ErrBadResponseCode}), ShouldBeTrue)
+		So(IsRetryable(&RequestError{Method: "users.get", StatusCode: 400, Err: ErrBadResponseCode}), ShouldBeFalse)
+		So(IsRetryable(&RequestError{Method: "users.get", StatusCode: 200, Err: io.ErrUnexpectedEOF}), ShouldBeTrue)
+		So(IsRetryable(&RequestError{Method: "users.get", StatusCode: 200, Err: &json.SyntaxError{Offset: 1}}), ShouldBeFalse)
+		So(IsRetryable(&RequestError{Method: "users.get", Err: &url.Error{Op: "Get", Err: context.Canceled}}), ShouldBeFalse)
+		So(IsRetryable(&RequestError{Method: "users.get", Err: &url.Error{Op: "Get", Err: context.DeadlineExceeded}}), ShouldBeFalse)
+		So(IsRetryable(&RequestError{Method: "users.get", Err: errors.New("bad url")}), ShouldBeFalse)
+		So(IsRetryable(io.EOF), ShouldBeFalse)
+		So(IsAuthError(io.EOF), ShouldBeFalse)
+	})
+}
@@ -1,8 +1,13 @@
 package vk
 
-import "fmt"
-import "bytes"
-import "text/template"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/ernado-legacy/vk/vkscript"
+)
 
 type Resource struct {
 	APIClient
@@ -10,7 +15,12 @@ type Resource struct {
 }
 
 func (r Resource) Decode(request Request, v interface{}) error {
-	res, err := r.Do(request)
+	return r.DecodeContext(context.Background(), request, v)
+}
+
+// DecodeContext performs request with context and decodes response to v
+func (r Resource) DecodeContext(ctx context.Context, request Request, v interface{}) error {
+	res, err := r.DoContext(ctx, request)
 	if err != nil {
 		return err
 	}
@@ -22,10 +32,20 @@ type Groups struct {
 }
 
 const (
-	methodGroupsGetMembers = "groups.getMembers"
-	methodGroupsGet        = "groups.get"
+	methodGroupsGetMembers     = "groups.getMembers"
+	methodGroupsGet            = "groups.get"
+	methodGroupsGetByID        = "groups.getById"
+	methodGroupsSearch         = "groups.search"
+	methodGroupsGetCatalog     = "groups.getCatalog"
+	methodGroupsGetCatalogInfo = "groups.getCatalogInfo"
+
+	// maxGroupsGetByIDs is limit of group ids in single groups.getById
+	maxGroupsGetByIDs = 500
 )
 
+// GroupFields are all fields of Group that are not returned by default
+const GroupFields = "activity,age_limits,city,contacts,counters,country,description,links,members_count,site,status,verified"
+
 //go:generate stringer -type=GroupType
 type GroupType int
 
@@ -52,7 +72,7 @@ const (
 
 type Group struct {
 	ID           int                    `json:"id"`
-	Name         string                 `json:"name`
+	Name         string                 `json:"name"`
 	Slug         string                 `json:"screen_name"`
 	Deactivated  GroupDeactivatedStatus `json:"deactivated"`
 	IsClosed     GroupType              `json:"is_closed"`
@@ -66,6 +86,54 @@ type Group struct {
 	Description  string                 `json:"description"`
 	MembersCount int                    `json:"members_count"`
 	Status       string                 `json:"status"`
+	Activity     string                 `json:"activity"`
+	AgeLimits    GroupAgeLimits         `json:"age_limits"`
+	City         City                   `json:"city"`
+	Country      Country                `json:"country"`
+	Verified     Bool                   `json:"verified"`
+	Site         string                 `json:"site"`
+	Counters     GroupCounters          `json:"counters"`
+	Contacts     []GroupContact         `json:"contacts"`
+	Links        []GroupLink            `json:"links"`
+}
+
+// GroupAgeLimits is age restriction of community
+type GroupAgeLimits int
+
+const (
+	GroupAgeUnknown GroupAgeLimits = 0
+	GroupAgeNone    GroupAgeLimits = 1
+	GroupAge16      GroupAgeLimits = 2
+	GroupAge18      GroupAgeLimits = 3
+)
+
+// GroupCounters are counts of community content
+type GroupCounters struct {
+	Photos int `json:"photos"`
+	Albums int `json:"albums"`
+	Audios int `json:"audios"`
+	Videos int `json:"videos"`
+	Topics int `json:"topics"`
+	Docs   int `json:"docs"`
+	Market int `json:"market"`
+}
+
+// GroupContact is contact person of community
+type GroupContact struct {
+	UserID      int    `json:"user_id"`
+	Description string `json:"desc"`
+	Phone       string `json:"phone"`
+	Email       string `json:"email"`
+}
+
+// GroupLink is link in community block of links
+type GroupLink struct {
+	ID          int    `json:"id"`
+	URL         string `json:"url"`
+	Name        string `json:"name"`
+	Description string `json:"desc"`
+	Photo50     string `json:"photo_50"`
+	Photo100    string `json:"photo_100"`
 }
 
 func (g Group) GetStatus() string {
@@ -79,23 +147,112 @@ func (g Group) String() string {
 	return fmt.Sprintf("G:%s %s [count=%d,status=%s]", g.Slug, g.Name, g.MembersCount, g.GetStatus())
 }
 
+// GroupMembersSort is order of community members
+type GroupMembersSort string
+
+const (
+	GroupMembersIDAsc    GroupMembersSort = "id_asc"
+	GroupMembersIDDesc   GroupMembersSort = "id_desc"
+	GroupMembersTimeAsc  GroupMembersSort = "time_asc"
+	GroupMembersTimeDesc GroupMembersSort = "time_desc"
+)
+
+// GroupMembersFilter restricts returned community members
+type GroupMembersFilter string
+
+const (
+	GroupMembersFriends  GroupMembersFilter = "friends"
+	GroupMembersUnsure   GroupMembersFilter = "unsure"
+	GroupMembersManagers GroupMembersFilter = "managers"
+	GroupMembersDonut    GroupMembersFilter = "donut"
+)
+
+// GroupMemberRole is role of community manager
+type GroupMemberRole string
+
+const (
+	GroupRoleModerator     GroupMemberRole = "moderator"
+	GroupRoleEditor        GroupMemberRole = "editor"
+	GroupRoleAdministrator GroupMemberRole = "administrator"
+	GroupRoleCreator       GroupMemberRole = "creator"
+)
+
 type GroupSearchFields struct {
-	ID int `structs:"id"`
+	ID int `url:"-"`
+	// ScreenName of community is used instead of ID if set
+	ScreenName string             `url:"-"`
+	Sort       GroupMembersSort   `url:"sort,omitempty"`
+	Filter     GroupMembersFilter `url:"filter,omitempty"`
+	Fields     string             `url:"fields,omitempty"`
+	Offset     int                `url:"offset,omitempty"`
+	Count      int                `url:"count,omitempty"`
+}
+
+// groupID returns value of group_id parameter
+func (f GroupSearchFields) groupID() string {
+	if len(f.ScreenName) != 0 {
+		return f.ScreenName
+	}
+	if f.ID == 0 {
+		return ""
+	}
+	return strconv.Itoa(f.ID)
+}
+
+// GroupMember is member of community, only ID is set
+// if no fields are requested. Role is set for managers.
+type GroupMember struct {
+	User
+	Role GroupMemberRole `json:"role"`
+}
+
+// UnmarshalJSON decodes member from object or from id
+func (m *GroupMember) UnmarshalJSON(data []byte) error {
+	if err := decodeUser(data, &m.User); err != nil {
+		return err
+	}
+	if len(data) == 0 || data[0] != '{' {
+		return nil
+	}
+	role := struct {
+		Role GroupMemberRole `json:"role"`
+	}{}
+	if err := json.Unmarshal(data, &role); err != nil {
+		return err
+	}
+	m.Role = role.Role
+	return nil
 }
 
 type GroupSearchResult struct {
-	Count int    `json:"count"`
-	Items []User `json:"items"`
+	Count int           `json:"count"`
+	Items []GroupMember `json:"items"`
 }
 
 type groupSearchResponse struct {
 	Error    `json:"error"`
-	Response GroupSearchResult `json:"response`
+	Response GroupSearchResult `json:"response"`
 }
 
 func (g Groups) GetMembers(q GroupSearchFields) (result GroupSearchResult, err error) {
-	request := g.Request(methodGroupsGetMembers, q)
-	return result, g.Decode(request, &result)
+	return g.GetMembersContext(context.Background(), q)
+}
+
+func (g Groups) GetMembersContext(ctx context.Context, q GroupSearchFields) (result GroupSearchResult, err error) {
+	return result, g.DecodeContext(ctx, g.membersRequest(q), &result)
+}
+
+// IterateMembers returns Iterator over GroupMember items,
+// Count of q is used as page size
+func (g Groups) IterateMembers(ctx context.Context, q GroupSearchFields) *Iterator {
+	return g.Iterate(ctx, g.membersRequest(q), q.Count)
+}
+
+func (g Groups) membersRequest(q GroupSearchFields) Request {
+	return g.Request(methodGroupsGetMembers, struct {
+		GroupSearchFields
+		GroupID string `url:"group_id,omitempty"`
+	}{q, q.groupID()})
 }
 
 type GroupGetFields struct {
@@ -113,53 +270,216 @@ type GroupGetResult struct {
 }
 
 func (g Groups) GetForUser(id int) ([]Group, error) {
+	return g.GetForUserContext(context.Background(), id)
+}
+
+func (g Groups) GetForUserContext(ctx context.Context, id int) ([]Group, error) {
 	result := &GroupGetResult{}
 	request := g.Request(methodGroupsGet, GroupGetFields{UserID: id,
 		Count:    1000,
 		Extended: true,
 		Fields:   "description,members_count",
 	})
-	return result.Items, g.Decode(request, &result)
+	return result.Items, g.DecodeContext(ctx, request, &result)
 }
 
 func (g Groups) Get(fields GroupGetFields) (result GroupGetResult, err error) {
-	return result, g.Decode(g.Request(methodGroupsGet, fields), &result)
+	return g.GetContext(context.Background(), fields)
+}
+
+func (g Groups) GetContext(ctx context.Context, fields GroupGetFields) (result GroupGetResult, err error) {
+	return result, g.DecodeContext(ctx, g.Request(methodGroupsGet, fields), &result)
+}
+
+// IterateGroups returns Iterator over communities of user, items are
+// Group if fields.Extended is set, otherwise ids. Count of fields
+// is used as page size.
+func (g Groups) IterateGroups(ctx context.Context, fields GroupGetFields) *Iterator {
+	return g.Iterate(ctx, g.Request(methodGroupsGet, fields), fields.Count)
 }
 
 // batch get
 func (g Groups) GetBatch(getFields GroupGetFields) ([]User, int, error) {
-	js := `var group_id = {{.GroupID}};
-	var count = 1000;
-	var offset = {{.Offset}};
-	var calls = 1;
-	var response = API.groups.getMembers({"count": count, "offset": offset, "group_id": group_id, fields: "{{ .Fields }}"});
-	var members_count = response.count;
-	var members = response.items;
-	offset = offset + count;
-	while ((offset < members_count) && (calls < 25)) {
-		response = API.groups.getMembers({"count": count, "offset": offset, "group_id": group_id, fields: "{{ .Fields }}"});
-		members = members + response.items;
-		members_count = response.count;
-		offset = offset + count;
-		calls = calls + 1;
-	}
-	return {count: members_count, members: members};`
+	return g.GetBatchContext(context.Background(), getFields)
+}
 
-	// rendering js from template
-	code := new(bytes.Buffer)
-	t := template.Must(template.New("get20k.js").Parse(js))
-	if err := t.Execute(code, getFields); err != nil {
+func (g Groups) GetBatchContext(ctx context.Context, getFields GroupGetFields) ([]User, int, error) {
+	code, err := groupGetBatchScript(getFields).Code()
+	if err != nil {
 		return nil, 0, err
 	}
 
 	// preparing request fields
 	fields := struct {
 		Code string `url:"code"`
-	}{Code: code.String()}
+	}{Code: code}
 	req := g.Request(methodExecute, fields)
+	// members are ids if no fields are requested
 	result := struct {
-		Count   int    `json:"count"`
-		Members []User `json:"members"`
+		Count   int           `json:"count"`
+		Members []GroupMember `json:"members"`
 	}{}
-	return result.Members, result.Count, g.Decode(req, &result)
+	if err := g.DecodeContext(ctx, req, &result); err != nil {
+		return nil, 0, err
+	}
+	users := make([]User, len(result.Members))
+	for i, m := range result.Members {
+		users[i] = m.User
+	}
+	return users, result.Count, nil
+}
+
+// groupGetBatchScript returns script that calls groups.getMembers
+// maxExecuteCalls times starting from offset
+func groupGetBatchScript(getFields GroupGetFields) *vkscript.Script {
+	const (
+		groupID      vkscript.Var = "group_id"
+		count        vkscript.Var = "count"
+		offset       vkscript.Var = "offset"
+		calls        vkscript.Var = "calls"
+		response     vkscript.Var = "response"
+		membersCount vkscript.Var = "members_count"
+		members      vkscript.Var = "members"
+	)
+	args := vkscript.Args{
+		"count":    count,
+		"offset":   offset,
+		"group_id": groupID,
+		"fields":   vkscript.String(getFields.Fields),
+	}
+	s := new(vkscript.Script)
+	s.Declare(groupID, vkscript.Int(getFields.GroupID))
+	s.Declare(count, vkscript.Int(1000))
+	s.Declare(offset, vkscript.Int(getFields.Offset))
+	s.Declare(calls, vkscript.Int(1))
+	s.Declare(response, vkscript.Call(methodGroupsGetMembers, args))
+	s.Declare(membersCount, vkscript.Field(response, "count"))
+	s.Declare(members, vkscript.Field(response, "items"))
+	s.Set(offset, vkscript.Add(offset, count))
+	cond := vkscript.And(vkscript.Less(offset, membersCount), vkscript.Less(calls, vkscript.Int(maxExecuteCalls)))
+	s.While(cond, maxExecuteCalls-1, func(s *vkscript.Script) {
+		s.Set(response, vkscript.Call(methodGroupsGetMembers, args))
+		s.Set(members, vkscript.Add(members, vkscript.Field(response, "items")))
+		s.Set(membersCount, vkscript.Field(response, "count"))
+		s.Set(offset, vkscript.Add(offset, count))
+		s.Set(calls, vkscript.Add(calls, vkscript.Int(1)))
+	})
+	s.Return(vkscript.Object(vkscript.Args{"count": membersCount, "members": members}))
+	return s
+}
+
+type GroupGetByIDFields struct {
+	// GroupIDs are ids or screen names of communities
+	GroupIDs []string `url:"group_ids,comma,omitempty"`
+	Fields   string   `url:"fields,omitempty"`
+}
+
+func (g Groups) GetByID(fields GroupGetByIDFields) ([]Group, error) {
+	return g.GetByIDContext(context.Background(), fields)
+}
+
+// GetByIDContext returns communities by ids or screen names,
+// ids are requested with chunks of maxGroupsGetByIDs.
+// Without ids current community of community token is returned.
+func (g Groups) GetByIDContext(ctx context.Context, fields GroupGetByIDFields) ([]Group, error) {
+	ids := fields.GroupIDs
+	var groups []Group
+	for {
+		chunk := ids
+		if len(chunk) > maxGroupsGetByIDs {
+			chunk = chunk[:maxGroupsGetByIDs]
+		}
+		fields.GroupIDs = chunk
+		var result []Group
+		if err := g.DecodeContext(ctx, g.Request(methodGroupsGetByID, fields), &result); err != nil {
+			return groups, err
+		}
+		groups = append(groups, result...)
+		if ids = ids[len(chunk):]; len(ids) == 0 {
+			return groups, nil
+		}
+	}
+}
+
+// GroupSearchType restricts type of communities in search
+type GroupSearchType string
+
+const (
+	GroupSearchGroup GroupSearchType = "group"
+	GroupSearchPage  GroupSearchType = "page"
+	GroupSearchEvent GroupSearchType = "event"
+)
+
+// GroupSearchSort is order of groups.search results
+type GroupSearchSort int
+
+const (
+	GroupSortDefault  GroupSearchSort = 0
+	GroupSortGrowth   GroupSearchSort = 1
+	GroupSortVisits   GroupSearchSort = 2
+	GroupSortLikes    GroupSearchSort = 3
+	GroupSortComments GroupSearchSort = 4
+	GroupSortTopics   GroupSearchSort = 5
+)
+
+type GroupSearchQuery struct {
+	Query     string          `url:"q"`
+	Type      GroupSearchType `url:"type,omitempty"`
+	CountryID CountryID       `url:"country_id,omitempty"`
+	CityID    int             `url:"city_id,omitempty"`
+	// Future restricts events to upcoming ones
+	Future Bool            `url:"future,omitempty"`
+	Market Bool            `url:"market,omitempty"`
+	Sort   GroupSearchSort `url:"sort,omitempty"`
+	Offset int             `url:"offset,omitempty"`
+	Count  int             `url:"count,omitempty"`
+}
+
+func (g Groups) Search(q GroupSearchQuery) (GroupGetResult, error) {
+	return g.SearchContext(context.Background(), q)
+}
+
+// SearchContext returns page of communities matching query,
+// vk returns at most 1000 communities for every query
+func (g Groups) SearchContext(ctx context.Context, q GroupSearchQuery) (result GroupGetResult, err error) {
+	return result, g.DecodeContext(ctx, g.Request(methodGroupsSearch, q), &result)
+}
+
+// GroupCatalogCategory is category of communities catalog
+type GroupCatalogCategory struct {
+	ID            int                    `json:"id"`
+	Name          string                 `json:"name"`
+	Subcategories []GroupCatalogCategory `json:"subcategories"`
+}
+
+// GroupCatalogInfo is list of catalog categories
+type GroupCatalogInfo struct {
+	Enabled    Bool                   `json:"enabled"`
+	Categories []GroupCatalogCategory `json:"categories"`
+}
+
+func (g Groups) GetCatalogInfo() (GroupCatalogInfo, error) {
+	return g.GetCatalogInfoContext(context.Background())
+}
+
+// GetCatalogInfoContext returns categories of catalog with subcategories
+func (g Groups) GetCatalogInfoContext(ctx context.Context) (info GroupCatalogInfo, err error) {
+	fields := struct {
+		Subcategories Bool `url:"subcategories"`
+	}{true}
+	return info, g.DecodeContext(ctx, g.Request(methodGroupsGetCatalogInfo, fields), &info)
+}
+
+func (g Groups) GetCatalog(categoryID, subcategoryID int) (GroupGetResult, error) {
+	return g.GetCatalogContext(context.Background(), categoryID, subcategoryID)
+}
+
+// GetCatalogContext returns communities of catalog category,
+// zero category is list of recommended communities
+func (g Groups) GetCatalogContext(ctx context.Context, categoryID, subcategoryID int) (result GroupGetResult, err error) {
+	fields := struct {
+		CategoryID    int `url:"category_id,omitempty"`
+		SubcategoryID int `url:"subcategory_id,omitempty"`
+	}{categoryID, subcategoryID}
+	return result, g.DecodeContext(ctx, g.Request(methodGroupsGetCatalog, fields), &result)
 }
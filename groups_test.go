@@ -1,6 +1,8 @@
 package vk
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"bytes"
@@ -14,6 +16,13 @@ type apiJSONMock struct {
 }
 
 func (api apiJSONMock) Do(req Request) (res *Response, err error) {
+	return api.DoContext(context.Background(), req)
+}
+
+func (api apiJSONMock) DoContext(ctx context.Context, req Request) (res *Response, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if api.err != nil {
 		return nil, api.err
 	}
@@ -71,6 +80,56 @@ func TestGroupsMethods(t *testing.T) {
 			So(f.request.Values.Get("offset"), ShouldEqual, "10")
 			So(f.request.Values.Get("extended"), ShouldEqual, "1")
 		})
+		Convey(methodGroupsGetMembers, func() {
+			mock := &requestsMock{responses: []string{`{"response": {"count": 3, "items": [1, 2, 3]}}`}}
+			g := Groups{record(mock, DefaultFactory)}
+			members, err := g.GetMembers(GroupSearchFields{
+				ID:     42,
+				Sort:   GroupMembersTimeDesc,
+				Filter: GroupMembersFriends,
+				Offset: 1000,
+				Count:  500,
+			})
+			So(err, ShouldBeNil)
+			So(members.Count, ShouldEqual, 3)
+			So(members.Items, ShouldHaveLength, 3)
+			So(members.Items[2].ID, ShouldEqual, 3)
+			values := mock.requests[0].Values
+			So(values.Get("group_id"), ShouldEqual, "42")
+			So(values.Get("sort"), ShouldEqual, "time_desc")
+			So(values.Get("filter"), ShouldEqual, "friends")
+			So(values.Get("offset"), ShouldEqual, "1000")
+			So(values.Get("count"), ShouldEqual, "500")
+			So(values.Get("fields"), ShouldBeBlank)
+
+			Convey("Screen name", func() {
+				_, err := g.GetMembers(GroupSearchFields{ID: 42, ScreenName: "apiclub", Fields: "sex"})
+				So(err, ShouldBeNil)
+				values := mock.requests[1].Values
+				So(values["group_id"], ShouldResemble, []string{"apiclub"})
+				So(values.Get("fields"), ShouldEqual, "sex")
+			})
+			Convey("Managers", func() {
+				mock.responses = []string{`{"response": {"count": 2, "items": [
+					{"id": 1, "role": "creator", "first_name": "Pavel"},
+					{"id": 2, "role": "editor"}
+				]}}`}
+				members, err := g.GetMembers(GroupSearchFields{ID: 1, Filter: GroupMembersManagers})
+				So(err, ShouldBeNil)
+				So(members.Items[0].Role, ShouldEqual, GroupRoleCreator)
+				So(members.Items[0].FirstName, ShouldEqual, "Pavel")
+				So(members.Items[1].Role, ShouldEqual, GroupRoleEditor)
+				So(members.Items[1].ID, ShouldEqual, 2)
+			})
+		})
+		Convey("Cancelled context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			f := rf()
+			g := Groups{record(newApiMock(`{"response": {"count": 0}}`, nil), &f)}
+			_, err := g.GetContext(ctx, GroupGetFields{})
+			So(err, ShouldEqual, context.Canceled)
+		})
 	})
 }
 
@@ -143,3 +202,117 @@ func TestGroups(t *testing.T) {
 		})
 	})
 }
+
+func TestGroupsLookup(t *testing.T) {
+	Convey("Groups lookup", t, func() {
+		Convey(methodGroupsGetByID, func() {
+			mock := &requestsMock{responses: []string{`{"response": [{
+				"id": 1, "name": "API", "screen_name": "apiclub", "verified": 1,
+				"activity": "Programming", "age_limits": 2, "site": "https://vk.com/dev",
+				"city": {"id": 2, "title": "Saint Petersburg"}, "country": {"id": 1, "title": "Russia"},
+				"counters": {"photos": 10, "topics": 3, "videos": 1},
+				"contacts": [{"user_id": 5, "desc": "Support"}],
+				"links": [{"id": 7, "url": "https://vk.com/dev", "name": "Docs"}]
+			}]}`}}
+			g := Groups{record(mock, DefaultFactory)}
+			groups, err := g.GetByID(GroupGetByIDFields{GroupIDs: []string{"apiclub"}, Fields: GroupFields})
+			So(err, ShouldBeNil)
+			So(groups, ShouldHaveLength, 1)
+			group := groups[0]
+			So(bool(group.Verified), ShouldBeTrue)
+			So(group.Activity, ShouldEqual, "Programming")
+			So(group.AgeLimits, ShouldEqual, GroupAge16)
+			So(group.City.ID, ShouldEqual, 2)
+			So(group.Country.Is(Russia), ShouldBeTrue)
+			So(group.Counters.Photos, ShouldEqual, 10)
+			So(group.Counters.Topics, ShouldEqual, 3)
+			So(group.Contacts[0].UserID, ShouldEqual, 5)
+			So(group.Links[0].Name, ShouldEqual, "Docs")
+			values := mock.requests[0].Values
+			So(values.Get("group_ids"), ShouldEqual, "apiclub")
+			So(values.Get("fields"), ShouldEqual, GroupFields)
+
+			Convey("Chunks", func() {
+				mock.requests = nil
+				ids := make([]int, maxGroupsGetByIDs*2+1)
+				for i := range ids {
+					ids[i] = i + 1
+				}
+				groups, err := g.GetByID(GroupGetByIDFields{GroupIDs: IDs(ids...)})
+				So(err, ShouldBeNil)
+				So(groups, ShouldHaveLength, 3)
+				So(mock.requests, ShouldHaveLength, 3)
+				So(mock.requests[2].Values.Get("group_ids"), ShouldEqual, "1001")
+			})
+			Convey("No ids", func() {
+				mock.requests = nil
+				_, err := g.GetByID(GroupGetByIDFields{Fields: GroupFields})
+				So(err, ShouldBeNil)
+				So(mock.requests, ShouldHaveLength, 1)
+				_, ok := mock.requests[0].Values["group_ids"]
+				So(ok, ShouldBeFalse)
+			})
+			Convey("Error", func() {
+				g := Groups{record(newApiMock("", ErrAuthFailed), DefaultFactory)}
+				_, err := g.GetByID(GroupGetByIDFields{GroupIDs: IDs(1)})
+				So(err, ShouldEqual, ErrAuthFailed)
+			})
+		})
+		Convey(methodGroupsSearch, func() {
+			mock := &requestsMock{responses: []string{`{"response": {"count": 2, "items": [{"id": 1}, {"id": 2}]}}`}}
+			g := Groups{record(mock, DefaultFactory)}
+			result, err := g.Search(GroupSearchQuery{
+				Query:     "golang",
+				Type:      GroupSearchPage,
+				CountryID: Russia,
+				CityID:    2,
+				Market:    true,
+				Sort:      GroupSortGrowth,
+				Count:     2,
+			})
+			So(err, ShouldBeNil)
+			So(result.Count, ShouldEqual, 2)
+			So(result.Items[1].ID, ShouldEqual, 2)
+			values := mock.requests[0].Values
+			So(values.Get("q"), ShouldEqual, "golang")
+			So(values.Get("type"), ShouldEqual, "page")
+			So(values.Get("country_id"), ShouldEqual, "1")
+			So(values.Get("city_id"), ShouldEqual, "2")
+			So(values.Get("market"), ShouldEqual, "1")
+			So(values.Get("sort"), ShouldEqual, "1")
+			So(values.Get("count"), ShouldEqual, "2")
+			So(values.Get("future"), ShouldBeBlank)
+		})
+		Convey("Catalog", func() {
+			mock := &requestsMock{responses: []string{
+				`{"response": {"enabled": 1, "categories": [{"id": 1, "name": "Games", "subcategories": [{"id": 10, "name": "Shooters"}]}]}}`,
+				`{"response": {"count": 1, "items": [{"id": 3, "name": "Game"}]}}`,
+			}}
+			g := Groups{record(mock, DefaultFactory)}
+			info, err := g.GetCatalogInfo()
+			So(err, ShouldBeNil)
+			So(bool(info.Enabled), ShouldBeTrue)
+			So(info.Categories[0].Subcategories[0].Name, ShouldEqual, "Shooters")
+			So(mock.requests[0].Values.Get("subcategories"), ShouldEqual, "1")
+
+			result, err := g.GetCatalog(1, 10)
+			So(err, ShouldBeNil)
+			So(result.Items[0].Name, ShouldEqual, "Game")
+			So(mock.requests[1].Method, ShouldEqual, methodGroupsGetCatalog)
+			So(mock.requests[1].Values.Get("category_id"), ShouldEqual, "1")
+			So(mock.requests[1].Values.Get("subcategory_id"), ShouldEqual, "10")
+		})
+	})
+}
+
+func TestGroupGetBatchScript(t *testing.T) {
+	Convey("Groups batch script", t, func() {
+		s := groupGetBatchScript(GroupGetFields{GroupID: 1, Offset: 1000, Fields: `sex"`})
+		So(s.Calls(), ShouldEqual, maxExecuteCalls)
+		code, err := s.Code()
+		So(err, ShouldBeNil)
+		So(code, ShouldStartWith, "var group_id = 1;\nvar count = 1000;\nvar offset = 1000;\n")
+		So(code, ShouldContainSubstring, `"fields":"sex\""`)
+		So(strings.Count(code, "API.groups.getMembers"), ShouldEqual, 2)
+	})
+}
@@ -0,0 +1,37 @@
+// Package cli has helpers shared by commands
+package cli
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/ernado-legacy/vk"
+)
+
+const (
+	envStore      = "VK_STORE"
+	envPassphrase = "VK_PASSPHRASE"
+	envUser       = "VK_USER"
+)
+
+// ErrNoUser is returned when token store is set, but user is not
+var ErrNoUser = errors.New("VK_USER is required to use token from VK_STORE")
+
+// NewClient returns client that uses token, or, if token is empty
+// and VK_STORE is set, token of VK_USER saved to store by vk-auth
+func NewClient(token string, options ...vk.Option) (*vk.Client, error) {
+	path := os.Getenv(envStore)
+	if len(token) != 0 || len(path) == 0 {
+		return vk.NewWithToken(token, options...), nil
+	}
+	user, err := strconv.Atoi(os.Getenv(envUser))
+	if err != nil || user == 0 {
+		return nil, ErrNoUser
+	}
+	store, err := vk.NewFileStore(path, os.Getenv(envPassphrase))
+	if err != nil {
+		return nil, err
+	}
+	return vk.NewWithStore(store, vk.UserIdentity(user), options...)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ernado-legacy/vk"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewClient(t *testing.T) {
+	Convey("Command client", t, func() {
+		dir, err := ioutil.TempDir("", "vk-cli")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "tokens.json")
+		store, err := vk.NewFileStore(path, "passphrase")
+		So(err, ShouldBeNil)
+		So(store.Put(vk.StoredToken{Identity: vk.UserIdentity(1), AccessToken: "token"}), ShouldBeNil)
+		for _, key := range []string{envStore, envPassphrase, envUser} {
+			defer os.Setenv(key, os.Getenv(key))
+			os.Unsetenv(key)
+		}
+
+		Convey("Token", func() {
+			os.Setenv(envStore, path)
+			_, err := NewClient("token")
+			So(err, ShouldBeNil)
+		})
+		Convey("No store", func() {
+			_, err := NewClient("")
+			So(err, ShouldBeNil)
+		})
+		Convey("Store", func() {
+			os.Setenv(envStore, path)
+			os.Setenv(envPassphrase, "passphrase")
+			os.Setenv(envUser, "1")
+			_, err := NewClient("")
+			So(err, ShouldBeNil)
+			os.Setenv(envUser, "2")
+			_, err = NewClient("")
+			So(errors.Is(err, vk.ErrTokenNotFound), ShouldBeTrue)
+		})
+		Convey("No user", func() {
+			os.Setenv(envStore, path)
+			os.Setenv(envPassphrase, "passphrase")
+			_, err := NewClient("")
+			So(err, ShouldEqual, ErrNoUser)
+		})
+		Convey("No passphrase", func() {
+			os.Setenv(envStore, path)
+			os.Setenv(envUser, "1")
+			_, err := NewClient("")
+			So(err, ShouldEqual, vk.ErrEmptyPassphrase)
+		})
+	})
+}
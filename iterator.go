@@ -0,0 +1,257 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/ernado-legacy/vk/vkscript"
+)
+
+const (
+	paramOffset = "offset"
+	paramCount  = "count"
+
+	// defaultPageSize is maximum page size of most list methods
+	defaultPageSize = 1000
+)
+
+// pageSizes are maximum page sizes of methods
+// that differ from defaultPageSize
+var pageSizes = map[string]int{
+	methodVideoGet: maxVideoGetCount,
+}
+
+// maxPageSize returns maximum page size of method
+func maxPageSize(method string) int {
+	if size, ok := pageSizes[method]; ok {
+		return size
+	}
+	return defaultPageSize
+}
+
+// page is response of offset based list method
+type page struct {
+	Count int               `json:"count"`
+	Items []json.RawMessage `json:"items"`
+	// Offset of next page, set only for batched pages
+	Offset int `json:"offset"`
+}
+
+// Iterator walks all items of offset based list method,
+// requesting next page when items of previous one are over.
+// Typical usage:
+//
+//	it := client.Groups.IterateMembers(ctx, fields)
+//	for it.Next() {
+//		var member GroupMember
+//		if err := it.Decode(&member); err != nil {
+//			return err
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+//
+// It is not safe for concurrent use.
+type Iterator struct {
+	ctx      context.Context
+	resource Resource
+	request  Request
+	pageSize int
+	limit    int
+	batch    int
+
+	offset  int
+	total   int
+	items   []json.RawMessage
+	current json.RawMessage
+	n       int
+	done    bool
+	err     error
+}
+
+// Iterate returns Iterator over items of request, that are requested
+// with pages of pageSize items starting from offset of request.
+// Maximum page size of method is used if pageSize is not positive.
+func (r Resource) Iterate(ctx context.Context, request Request, pageSize int) *Iterator {
+	if pageSize <= 0 {
+		pageSize = maxPageSize(request.Method)
+	}
+	offset, _ := strconv.Atoi(request.Values.Get(paramOffset))
+	return &Iterator{
+		ctx:      ctx,
+		resource: r,
+		request:  request,
+		pageSize: pageSize,
+		offset:   offset,
+	}
+}
+
+// SetLimit sets maximum count of items returned by iterator,
+// zero means no limit
+func (it *Iterator) SetLimit(limit int) {
+	it.limit = limit
+}
+
+// SetBatch sets count of pages that are requested with single
+// execute call, up to maxExecuteCalls. Values less than two
+// disable batching.
+func (it *Iterator) SetBatch(pages int) {
+	if pages > maxExecuteCalls {
+		pages = maxExecuteCalls
+	}
+	it.batch = pages
+}
+
+// Next advances iterator to next item, returning false
+// when items are over or error occurred
+func (it *Iterator) Next() bool {
+	if it.err != nil || (it.limit > 0 && it.n >= it.limit) {
+		return false
+	}
+	if len(it.items) == 0 {
+		if it.done {
+			return false
+		}
+		if it.err = it.fetch(); it.err != nil {
+			return false
+		}
+		if len(it.items) == 0 {
+			it.done = true
+			return false
+		}
+	}
+	it.current = it.items[0]
+	it.items = it.items[1:]
+	it.n++
+	return true
+}
+
+// Decode decodes current item to v
+func (it *Iterator) Decode(v interface{}) error {
+	return json.Unmarshal(it.current, v)
+}
+
+// Err returns error that stopped iteration
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Total returns total count of items reported by vk,
+// it is known only after first call of Next
+func (it *Iterator) Total() int {
+	return it.total
+}
+
+// pages returns count of pages to request
+func (it *Iterator) pages() int {
+	pages := it.batch
+	if pages < 2 {
+		return 1
+	}
+	if it.limit > 0 {
+		left := it.limit - it.n
+		if max := (left + it.pageSize - 1) / it.pageSize; max < pages {
+			pages = max
+		}
+	}
+	return pages
+}
+
+// fetch requests next pages
+func (it *Iterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+	var (
+		p     page
+		err   error
+		pages = it.pages()
+	)
+	if pages < 2 {
+		err = it.resource.DecodeContext(it.ctx, it.pageRequest(), &p)
+		p.Offset = it.offset + it.pageSize
+	} else {
+		err = it.batchPages(pages, &p)
+	}
+	if err != nil {
+		return err
+	}
+	it.total = p.Count
+	it.items = p.Items
+	it.offset = p.Offset
+	if it.offset >= it.total {
+		it.done = true
+	}
+	return nil
+}
+
+// pageRequest returns request of page at current offset
+func (it *Iterator) pageRequest() Request {
+	r := it.request
+	r.Values = url.Values{}
+	for k, v := range it.request.Values {
+		r.Values[k] = v
+	}
+	r.Values.Set(paramOffset, strconv.Itoa(it.offset))
+	r.Values.Set(paramCount, strconv.Itoa(it.pageSize))
+	return r
+}
+
+// batchPages requests pages with single execute call
+func (it *Iterator) batchPages(pages int, p *page) error {
+	code, err := pagesScript(it.pageRequest(), pages).Code()
+	if err != nil {
+		return err
+	}
+	values := url.Values{}
+	values.Set("code", code)
+	request := Request{
+		Method: methodExecute,
+		Token:  it.request.Token,
+		Values: values,
+	}
+	return it.resource.DecodeContext(it.ctx, request, p)
+}
+
+// pagesScript returns script that calls method of request up to pages
+// times, starting from offset of request while items are not over
+func pagesScript(r Request, pages int) *vkscript.Script {
+	const (
+		offset   vkscript.Var = "offset"
+		size     vkscript.Var = "size"
+		calls    vkscript.Var = "calls"
+		response vkscript.Var = "response"
+		count    vkscript.Var = "count"
+		items    vkscript.Var = "items"
+	)
+	requestOffset, _ := strconv.Atoi(r.Values.Get(paramOffset))
+	pageSize, _ := strconv.Atoi(r.Values.Get(paramCount))
+	args := vkscript.Args{}
+	for k := range r.Values {
+		args[k] = vkscript.String(r.Values.Get(k))
+	}
+	args[paramOffset] = offset
+	args[paramCount] = size
+
+	s := new(vkscript.Script)
+	s.Declare(offset, vkscript.Int(requestOffset))
+	s.Declare(size, vkscript.Int(pageSize))
+	s.Declare(calls, vkscript.Int(1))
+	s.Declare(response, vkscript.Call(r.Method, args))
+	s.Declare(count, vkscript.Field(response, "count"))
+	s.Declare(items, vkscript.Field(response, "items"))
+	s.Set(offset, vkscript.Add(offset, size))
+	cond := vkscript.And(vkscript.Less(offset, count), vkscript.Less(calls, vkscript.Int(pages)))
+	s.While(cond, pages-1, func(s *vkscript.Script) {
+		s.Set(response, vkscript.Call(r.Method, args))
+		s.Set(items, vkscript.Add(items, vkscript.Field(response, "items")))
+		s.Set(count, vkscript.Field(response, "count"))
+		s.Set(offset, vkscript.Add(offset, size))
+		s.Set(calls, vkscript.Add(calls, vkscript.Int(1)))
+	})
+	s.Return(vkscript.Object(vkscript.Args{"count": count, "items": items, "offset": offset}))
+	return s
+}
@@ -0,0 +1,180 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	scriptOffsetRegexp = regexp.MustCompile(`var offset = (\d+);`)
+	scriptSizeRegexp   = regexp.MustCompile(`var size = (\d+);`)
+	scriptPagesRegexp  = regexp.MustCompile(`\(calls < (\d+)\)`)
+)
+
+// pagesMock serves total items with ids from 1 to total,
+// emulating execute of pagesScript
+type pagesMock struct {
+	total int
+
+	mux      sync.Mutex
+	requests []Request
+}
+
+func (m *pagesMock) Do(req Request) (*Response, error) {
+	return m.DoContext(context.Background(), req)
+}
+
+func (m *pagesMock) items(offset, count int) []int {
+	var items []int
+	for i := offset; i < offset+count && i < m.total; i++ {
+		items = append(items, i+1)
+	}
+	return items
+}
+
+func (m *pagesMock) DoContext(ctx context.Context, req Request) (*Response, error) {
+	m.mux.Lock()
+	m.requests = append(m.requests, req)
+	m.mux.Unlock()
+	result := struct {
+		Count  int   `json:"count"`
+		Items  []int `json:"items"`
+		Offset int   `json:"offset,omitempty"`
+	}{Count: m.total}
+	if req.Method == methodExecute {
+		code := req.Values.Get("code")
+		atoi := func(re *regexp.Regexp) int {
+			v, _ := strconv.Atoi(re.FindStringSubmatch(code)[1])
+			return v
+		}
+		offset, size, pages := atoi(scriptOffsetRegexp), atoi(scriptSizeRegexp), atoi(scriptPagesRegexp)
+		for calls := 0; calls < pages && (calls == 0 || offset < m.total); calls++ {
+			result.Items = append(result.Items, m.items(offset, size)...)
+			offset += size
+		}
+		result.Offset = offset
+	} else {
+		offset, _ := strconv.Atoi(req.Values.Get(paramOffset))
+		count, _ := strconv.Atoi(req.Values.Get(paramCount))
+		result.Items = m.items(offset, count)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return newApiMock(fmt.Sprintf(`{"response": %s}`, data), nil).DoContext(ctx, req)
+}
+
+func collect(it *Iterator) []int {
+	var ids []int
+	for it.Next() {
+		var member GroupMember
+		So(it.Decode(&member), ShouldBeNil)
+		ids = append(ids, member.ID)
+	}
+	return ids
+}
+
+func TestIterator(t *testing.T) {
+	Convey("Iterator", t, func() {
+		mock := &pagesMock{total: 25}
+		g := Groups{record(mock, DefaultFactory)}
+		ctx := context.Background()
+
+		Convey("Pages", func() {
+			it := g.IterateMembers(ctx, GroupSearchFields{ID: 1, Count: 10})
+			ids := collect(it)
+			So(it.Err(), ShouldBeNil)
+			So(it.Total(), ShouldEqual, 25)
+			So(ids, ShouldHaveLength, 25)
+			So(ids[0], ShouldEqual, 1)
+			So(ids[24], ShouldEqual, 25)
+			So(mock.requests, ShouldHaveLength, 3)
+			So(mock.requests[2].Values.Get("offset"), ShouldEqual, "20")
+			So(mock.requests[2].Values.Get("group_id"), ShouldEqual, "1")
+		})
+		Convey("Offset", func() {
+			ids := collect(g.IterateMembers(ctx, GroupSearchFields{ID: 1, Count: 10, Offset: 20}))
+			So(ids, ShouldResemble, []int{21, 22, 23, 24, 25})
+			So(mock.requests, ShouldHaveLength, 1)
+		})
+		Convey("Limit", func() {
+			it := g.IterateMembers(ctx, GroupSearchFields{ID: 1, Count: 10})
+			it.SetLimit(12)
+			So(collect(it), ShouldHaveLength, 12)
+			So(mock.requests, ShouldHaveLength, 2)
+		})
+		Convey("Empty", func() {
+			mock.total = 0
+			it := g.IterateMembers(ctx, GroupSearchFields{ID: 1})
+			So(it.Next(), ShouldBeFalse)
+			So(it.Err(), ShouldBeNil)
+			So(mock.requests, ShouldHaveLength, 1)
+		})
+		Convey("Batch", func() {
+			mock.total = 95
+			it := g.IterateMembers(ctx, GroupSearchFields{ID: 1, Count: 10, Fields: "sex"})
+			it.SetBatch(4)
+			ids := collect(it)
+			So(it.Err(), ShouldBeNil)
+			So(ids, ShouldHaveLength, 95)
+			So(ids[94], ShouldEqual, 95)
+			So(mock.requests, ShouldHaveLength, 3)
+			code := mock.requests[0].Values.Get("code")
+			So(code, ShouldContainSubstring, `API.groups.getMembers({"count":size,"fields":"sex","group_id":"1","offset":offset})`)
+			So(mock.requests[2].Values.Get("code"), ShouldContainSubstring, "var offset = 80;")
+
+			Convey("Limit", func() {
+				mock.requests = nil
+				it := g.IterateMembers(ctx, GroupSearchFields{ID: 1, Count: 10})
+				it.SetBatch(maxExecuteCalls + 10)
+				it.SetLimit(25)
+				So(collect(it), ShouldHaveLength, 25)
+				So(mock.requests, ShouldHaveLength, 1)
+				So(mock.requests[0].Values.Get("code"), ShouldContainSubstring, "(calls < 3)")
+			})
+		})
+		Convey("Error", func() {
+			g := Groups{record(newApiMock("", ErrAuthFailed), DefaultFactory)}
+			it := g.IterateMembers(ctx, GroupSearchFields{ID: 1})
+			So(it.Next(), ShouldBeFalse)
+			So(it.Err(), ShouldEqual, ErrAuthFailed)
+			So(it.Next(), ShouldBeFalse)
+		})
+		Convey("Cancelled context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			it := g.IterateMembers(ctx, GroupSearchFields{ID: 1, Count: 10})
+			So(it.Next(), ShouldBeTrue)
+			cancel()
+			for it.Next() {
+			}
+			So(it.Err(), ShouldEqual, context.Canceled)
+			So(mock.requests, ShouldHaveLength, 1)
+		})
+		Convey("Groups", func() {
+			ids := collect(g.IterateGroups(ctx, GroupGetFields{UserID: 1}))
+			So(ids, ShouldHaveLength, 25)
+			So(mock.requests[0].Method, ShouldEqual, methodGroupsGet)
+			So(mock.requests[0].Values.Get("count"), ShouldEqual, "1000")
+		})
+		Convey("Video", func() {
+			v := Video{record(mock, DefaultFactory)}
+			it := v.IterateVideos(ctx, VideoGetFields{})
+			n := 0
+			for it.Next() {
+				n++
+			}
+			So(it.Err(), ShouldBeNil)
+			So(n, ShouldEqual, 25)
+			So(mock.requests[0].Method, ShouldEqual, methodVideoGet)
+			So(mock.requests[0].Values.Get("count"), ShouldEqual, "200")
+		})
+	})
+}
@@ -0,0 +1,178 @@
+package vk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter restricts rate of requests to vk api
+type Limiter interface {
+	// Wait blocks until request with provided access token
+	// can be performed or context is done
+	Wait(ctx context.Context, token string) error
+}
+
+// clock provides time for RateLimiter, so tests can control it
+type clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, d)
+}
+
+// TokenKind is kind of access token; vk applies
+// different rate limits to every kind
+type TokenKind string
+
+// token kinds
+const (
+	TokenUser    TokenKind = "user"
+	TokenGroup   TokenKind = "group"
+	TokenService TokenKind = "service"
+)
+
+// rate is allowed request rate for single access token
+type rate struct {
+	interval time.Duration
+	burst    float64
+}
+
+func newRate(interval time.Duration, burst int) rate {
+	if burst < 1 {
+		burst = 1
+	}
+	return rate{interval: interval, burst: float64(burst)}
+}
+
+// bucket is token bucket state for single access token
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter is token bucket Limiter that tracks
+// every access token separately, because user, group
+// and service tokens are limited by vk independently.
+// Tokens of kind with rate set by SetRate are limited
+// with that rate, all other tokens with default one.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	rate  rate
+	clock clock
+
+	mux     sync.Mutex
+	rates   map[TokenKind]rate
+	kinds   map[string]TokenKind
+	buckets map[string]*bucket
+}
+
+// NewRateLimiter returns limiter that allows one request per interval
+// for each token, accumulating at most burst requests
+func NewRateLimiter(interval time.Duration, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    newRate(interval, burst),
+		clock:   realClock{},
+		rates:   make(map[TokenKind]rate),
+		kinds:   make(map[string]TokenKind),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// SetRate sets interval and burst for tokens of provided kind
+func (l *RateLimiter) SetRate(kind TokenKind, interval time.Duration, burst int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.rates[kind] = newRate(interval, burst)
+}
+
+// SetKind sets kind of access token, so it is limited with rate of kind
+func (l *RateLimiter) SetKind(token string, kind TokenKind) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.kinds[token] = kind
+}
+
+// rateOf returns rate for provided access token
+func (l *RateLimiter) rateOf(token string) rate {
+	if r, ok := l.rates[l.kinds[token]]; ok {
+		return r
+	}
+	return l.rate
+}
+
+// reserve takes one token from bucket of provided access token
+// and returns duration that caller must wait before request
+func (l *RateLimiter) reserve(token string) time.Duration {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.clock.Now()
+	r := l.rateOf(token)
+	b, ok := l.buckets[token]
+	if !ok {
+		b = &bucket{tokens: r.burst, last: now}
+		l.buckets[token] = b
+	}
+	if r.interval > 0 {
+		b.tokens += float64(now.Sub(b.last)) / float64(r.interval)
+	}
+	if b.tokens > r.burst {
+		b.tokens = r.burst
+	}
+	b.last = now
+
+	// tokens can go below zero, so concurrent callers
+	// are queued one interval after another
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(r.interval))
+}
+
+// Wait blocks until request with provided token is allowed
+// or context is done. Cancelled wait still consumes reservation.
+func (l *RateLimiter) Wait(ctx context.Context, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.clock.Sleep(ctx, l.reserve(token))
+}
+
+// sleep pauses for provided duration or until context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// kindLimiter is Limiter that limits tokens of kinds differently
+type kindLimiter interface {
+	SetKind(token string, kind TokenKind)
+}
+
+// DefaultLimiter returns new limiter for documented vk limits:
+// maxRequestsPerSecond requests per second for user tokens,
+// maxGroupRequestsPerSecond for community tokens and
+// maxServiceRequestsPerSecond for service tokens. Tokens with
+// unknown kind are limited as user ones.
+func DefaultLimiter() *RateLimiter {
+	l := NewRateLimiter(minimumRate, 1)
+	l.SetRate(TokenGroup, minimumGroupRate, 1)
+	l.SetRate(TokenService, minimumServiceRate, 1)
+	return l
+}
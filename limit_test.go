@@ -0,0 +1,274 @@
+package vk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type sleeper struct {
+	until time.Time
+	wake  chan struct{}
+}
+
+// fakeClock is clock that moves only when advance is called,
+// so request times do not depend on scheduling of goroutines
+type fakeClock struct {
+	mux      sync.Mutex
+	now      time.Time
+	sleepers []sleeper
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	c.mux.Lock()
+	s := sleeper{until: c.now.Add(d), wake: make(chan struct{})}
+	c.sleepers = append(c.sleepers, s)
+	c.mux.Unlock()
+	select {
+	case <-s.wake:
+		return nil
+	case <-ctx.Done():
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		for i := range c.sleepers {
+			if c.sleepers[i].wake == s.wake {
+				c.sleepers = append(c.sleepers[:i], c.sleepers[i+1:]...)
+				break
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// sleeping returns count of blocked sleepers
+func (c *fakeClock) sleeping() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return len(c.sleepers)
+}
+
+// advance moves time to earliest wake up and wakes sleepers that are due
+func (c *fakeClock) advance() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if len(c.sleepers) == 0 {
+		return
+	}
+	next := c.sleepers[0].until
+	for _, s := range c.sleepers {
+		if s.until.Before(next) {
+			next = s.until
+		}
+	}
+	c.now = next
+	left := c.sleepers[:0]
+	for _, s := range c.sleepers {
+		if s.until.After(c.now) {
+			left = append(left, s)
+			continue
+		}
+		close(s.wake)
+	}
+	c.sleepers = left
+}
+
+// timeRecorder records clock time of every request per access token
+type timeRecorder struct {
+	clock *fakeClock
+	mux   sync.Mutex
+	times map[string][]time.Time
+}
+
+func (r *timeRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.Lock()
+	token := req.URL.Query().Get(paramToken)
+	r.times[token] = append(r.times[token], r.clock.Now())
+	r.mux.Unlock()
+	w.Write([]byte(`{"response": 1}`))
+}
+
+// sorted returns recorded times of token in ascending order
+func (r *timeRecorder) sorted(token string) []time.Time {
+	times := r.times[token]
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// minSpacing returns minimum interval between sorted times
+func minSpacing(times []time.Time) time.Duration {
+	min := time.Duration(-1)
+	for i := 1; i < len(times); i++ {
+		if d := times[i].Sub(times[i-1]); min < 0 || d < min {
+			min = d
+		}
+	}
+	return min
+}
+
+func TestRateLimiter(t *testing.T) {
+	Convey("Rate limiter", t, func() {
+		start := time.Unix(1500000000, 0)
+		clock := &fakeClock{now: start}
+		recorder := &timeRecorder{clock: clock, times: make(map[string][]time.Time)}
+		server := httptest.NewServer(recorder)
+		defer server.Close()
+
+		const (
+			callers  = 10
+			interval = 100 * time.Millisecond
+		)
+		newLimiter := func(burst int) *RateLimiter {
+			limiter := NewRateLimiter(interval, burst)
+			limiter.clock = clock
+			return limiter
+		}
+		client := New(
+			WithBaseURL(server.URL),
+			WithLimiter(newLimiter(1)),
+		)
+
+		// do performs requests concurrently, moving clock only when every
+		// caller is either blocked by limiter or done, so requests arrive
+		// to server exactly at times scheduled by limiter
+		do := func(tokens ...string) {
+			total := len(tokens) * callers
+			errs := make(chan error, total)
+			var done int32
+			for _, token := range tokens {
+				for i := 0; i < callers; i++ {
+					go func(token string) {
+						_, err := client.Do(Request{Method: "users.get", Token: token})
+						errs <- err
+						atomic.AddInt32(&done, 1)
+					}(token)
+				}
+			}
+			for int(atomic.LoadInt32(&done)) < total {
+				if clock.sleeping()+int(atomic.LoadInt32(&done)) == total {
+					clock.advance()
+					continue
+				}
+				time.Sleep(time.Millisecond)
+			}
+			close(errs)
+			for err := range errs {
+				So(err, ShouldBeNil)
+			}
+		}
+
+		Convey("Concurrent callers do not exceed rate", func() {
+			do("token")
+			times := recorder.sorted("token")
+			So(times, ShouldHaveLength, callers)
+			So(times[0], ShouldEqual, start)
+			So(minSpacing(times), ShouldEqual, interval)
+			So(times[callers-1].Sub(start), ShouldEqual, (callers-1)*interval)
+		})
+		Convey("Tokens are limited separately", func() {
+			do("first", "second")
+			for _, token := range []string{"first", "second"} {
+				times := recorder.sorted(token)
+				So(times, ShouldHaveLength, callers)
+				So(minSpacing(times), ShouldEqual, interval)
+				So(times[callers-1].Sub(start), ShouldEqual, (callers-1)*interval)
+			}
+		})
+		Convey("Burst", func() {
+			client.SetLimiter(newLimiter(3))
+			do("token")
+			times := recorder.sorted("token")
+			So(times, ShouldHaveLength, callers)
+			So(times[2], ShouldEqual, start)
+			So(minSpacing(times[2:]), ShouldEqual, interval)
+			So(times[callers-1].Sub(start), ShouldEqual, (callers-3)*interval)
+		})
+		Convey("Token kinds", func() {
+			limiter := newLimiter(1)
+			limiter.SetRate(TokenGroup, interval/4, 1)
+			limiter.SetKind("group", TokenGroup)
+			client.SetLimiter(limiter)
+			do("user", "group")
+			times := recorder.sorted("user")
+			So(times, ShouldHaveLength, callers)
+			So(minSpacing(times), ShouldEqual, interval)
+			So(times[callers-1].Sub(start), ShouldEqual, (callers-1)*interval)
+			times = recorder.sorted("group")
+			So(times, ShouldHaveLength, callers)
+			So(minSpacing(times), ShouldEqual, interval/4)
+			So(times[callers-1].Sub(start), ShouldEqual, (callers-1)*interval/4)
+		})
+		Convey("Default limits", func() {
+			limiter := DefaultLimiter()
+			limiter.clock = clock
+			limiter.SetKind("group", TokenGroup)
+			client.SetLimiter(limiter)
+			do("user", "group")
+			So(minSpacing(recorder.sorted("user")), ShouldEqual, minimumRate)
+			So(minSpacing(recorder.sorted("group")), ShouldEqual, minimumGroupRate)
+		})
+		Convey("Disabled", func() {
+			client.SetLimiter(nil)
+			do("token")
+			times := recorder.sorted("token")
+			So(times, ShouldHaveLength, callers)
+			So(times[callers-1], ShouldEqual, start)
+		})
+	})
+}
+
+func TestClientTokenKinds(t *testing.T) {
+	Convey("Client token kinds", t, func() {
+		rateOf := func(c *Client, token string) rate {
+			return c.Limiter().(*RateLimiter).rateOf(token)
+		}
+		user, group := newRate(minimumRate, 1), newRate(minimumGroupRate, 1)
+
+		Convey("Unknown token is user one", func() {
+			So(rateOf(NewWithToken("token"), "token"), ShouldResemble, user)
+		})
+		Convey("Option", func() {
+			c := NewWithToken("token", WithTokenKind("token", TokenGroup))
+			So(rateOf(c, "token"), ShouldResemble, group)
+		})
+		Convey("Kinds are passed to new limiter", func() {
+			c := NewWithToken("token", WithTokenKind("token", TokenGroup), WithLimiter(nil))
+			So(c.Limiter(), ShouldBeNil)
+			c.SetLimiter(DefaultLimiter())
+			So(rateOf(c, "token"), ShouldResemble, group)
+		})
+		Convey("Pool", func() {
+			c := NewWithPool(NewTokenPoolOf(TokenGroup, "first", "second"))
+			So(rateOf(c, "first"), ShouldResemble, group)
+			So(rateOf(c, "second"), ShouldResemble, group)
+			c = NewWithPool(NewTokenPool("first"))
+			So(rateOf(c, "first"), ShouldResemble, user)
+		})
+		Convey("Store", func() {
+			store := NewMemoryStore()
+			store.Put(StoredToken{Identity: UserIdentity(1), AccessToken: "user"})
+			store.Put(StoredToken{Identity: GroupIdentity(1), AccessToken: "group"})
+			c, err := NewWithStore(store, UserIdentity(1))
+			So(err, ShouldBeNil)
+			So(rateOf(c, "user"), ShouldResemble, user)
+			c, err = NewWithStore(store, GroupIdentity(1))
+			So(err, ShouldBeNil)
+			So(rateOf(c, "group"), ShouldResemble, group)
+		})
+	})
+}
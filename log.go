@@ -0,0 +1,85 @@
+package vk
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Keys of structured log fields
+const (
+	LogMethod    = "method"
+	LogAttempt   = "attempt"
+	LogLatency   = "latency"
+	LogStatus    = "status"
+	LogErrorCode = "error_code"
+	LogError     = "error"
+	LogDelay     = "delay"
+
+	redacted = "[redacted]"
+)
+
+// Fields of structured log entry
+type Fields map[string]interface{}
+
+// Logger receives structured client events.
+// Access tokens are never passed to logger.
+type Logger interface {
+	Log(msg string, fields Fields)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(msg string, fields Fields) {}
+
+type stdLogger struct {
+	logger *log.Logger
+}
+
+// StdLogger returns Logger that writes entries to l
+// as message followed by sorted key=value pairs
+func StdLogger(l *log.Logger) Logger {
+	return stdLogger{l}
+}
+
+func (l stdLogger) Log(msg string, fields Fields) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	entry := []string{msg}
+	for _, k := range keys {
+		entry = append(entry, fmt.Sprintf("%s=%v", k, fields[k]))
+	}
+	l.logger.Println(strings.Join(entry, " "))
+}
+
+// redact removes token from s
+func redact(s, token string) string {
+	if len(token) == 0 {
+		return s
+	}
+	return strings.Replace(s, token, redacted, -1)
+}
+
+// logAttempt logs result of single request attempt
+func (c *Client) logAttempt(request Request, a Attempt, latency time.Duration, err error) {
+	fields := Fields{
+		LogMethod:  request.Method,
+		LogAttempt: a.Number,
+		LogLatency: latency,
+	}
+	if a.StatusCode != 0 {
+		fields[LogStatus] = a.StatusCode
+	}
+	if a.Code != ErrZero {
+		fields[LogErrorCode] = int(a.Code)
+	}
+	if err != nil {
+		fields[LogError] = redact(err.Error(), request.Token)
+	}
+	c.logger.Log("request", fields)
+}
@@ -0,0 +1,98 @@
+package vk
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type logEntry struct {
+	msg    string
+	fields Fields
+}
+
+type recordLogger struct {
+	mux     sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordLogger) Log(msg string, fields Fields) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.entries = append(l.entries, logEntry{msg, fields})
+}
+
+func TestLogger(t *testing.T) {
+	Convey("Logger", t, func() {
+		const token = "secret-token"
+		status := http.StatusBadGateway
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				status = http.StatusOK
+				return
+			}
+			fmt.Fprintf(w, `{"error": {"error_code": %d, "error_msg": "User authorization failed"}}`, ErrAuthFailed)
+		}))
+		defer server.Close()
+
+		logger := new(recordLogger)
+		client := NewWithToken(token,
+			WithBaseURL(server.URL),
+			WithLimiter(nil),
+			WithLogger(logger),
+			WithRetryPolicy(Backoff{Attempts: 3, Base: time.Millisecond, Max: time.Millisecond}),
+		)
+		_, err := client.Do(Request{Method: "users.get", Token: token})
+		So(ErrAuthFailed.Is(err), ShouldBeTrue)
+		So(logger.entries, ShouldHaveLength, 3)
+
+		first := logger.entries[0]
+		So(first.msg, ShouldEqual, "request")
+		So(first.fields[LogMethod], ShouldEqual, "users.get")
+		So(first.fields[LogAttempt], ShouldEqual, 1)
+		So(first.fields[LogStatus], ShouldEqual, http.StatusBadGateway)
+		So(first.fields[LogErrorCode], ShouldEqual, int(ErrBadResponseCode))
+		So(first.fields[LogLatency], ShouldHaveSameTypeAs, time.Duration(0))
+
+		So(logger.entries[1].msg, ShouldEqual, "retry")
+
+		last := logger.entries[2]
+		So(last.fields[LogAttempt], ShouldEqual, 2)
+		So(last.fields[LogStatus], ShouldEqual, http.StatusOK)
+		So(last.fields[LogErrorCode], ShouldEqual, int(ErrAuthFailed))
+
+		Convey("Token is redacted", func() {
+			client := NewWithToken(token,
+				WithBaseURL("http://127.0.0.1:0"),
+				WithLimiter(nil),
+				WithLogger(logger),
+				WithRetryPolicy(nil),
+			)
+			_, err := client.Do(Request{Method: "users.get", Token: token})
+			So(err, ShouldNotBeNil)
+			entry := logger.entries[len(logger.entries)-1]
+			So(entry.fields[LogError], ShouldNotBeBlank)
+			for _, e := range logger.entries {
+				for _, v := range e.fields {
+					So(fmt.Sprint(v), ShouldNotContainSubstring, token)
+				}
+			}
+		})
+	})
+	Convey("Silent by default", t, func() {
+		So(New().logger, ShouldHaveSameTypeAs, nopLogger{})
+	})
+	Convey("Std logger", t, func() {
+		buf := new(bytes.Buffer)
+		StdLogger(log.New(buf, "", 0)).Log("request", Fields{LogMethod: "users.get", LogAttempt: 1})
+		So(buf.String(), ShouldEqual, "request attempt=1 method=users.get\n")
+	})
+}
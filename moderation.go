@@ -0,0 +1,217 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	methodGroupsJoin           = "groups.join"
+	methodGroupsLeave          = "groups.leave"
+	methodGroupsInvite         = "groups.invite"
+	methodGroupsApproveRequest = "groups.approveRequest"
+	methodGroupsRemoveUser     = "groups.removeUser"
+	methodGroupsGetRequests    = "groups.getRequests"
+	methodGroupsBan            = "groups.ban"
+	methodGroupsUnban          = "groups.unban"
+	methodGroupsGetBanned      = "groups.getBanned"
+)
+
+// groupUserFields are arguments of methods on user in community
+type groupUserFields struct {
+	GroupID int `url:"group_id"`
+	UserID  int `url:"user_id,omitempty"`
+}
+
+// perform calls community method that returns 1 on success,
+// access errors are returned as PermissionError
+func (g Groups) perform(ctx context.Context, method string, groupID int, fields interface{}) error {
+	var result int
+	err := g.DecodeContext(ctx, g.Request(method, fields), &result)
+	return groupPermissionError(method, groupID, err)
+}
+
+func (g Groups) Join(groupID int, notSure bool) error {
+	return g.JoinContext(context.Background(), groupID, notSure)
+}
+
+// JoinContext joins community or sends request to join closed one,
+// notSure is used for events when user is not sure to attend
+func (g Groups) JoinContext(ctx context.Context, groupID int, notSure bool) error {
+	fields := struct {
+		GroupID int  `url:"group_id"`
+		NotSure Bool `url:"not_sure,omitempty"`
+	}{groupID, Bool(notSure)}
+	return g.perform(ctx, methodGroupsJoin, groupID, fields)
+}
+
+func (g Groups) Leave(groupID int) error {
+	return g.LeaveContext(context.Background(), groupID)
+}
+
+// LeaveContext leaves community
+func (g Groups) LeaveContext(ctx context.Context, groupID int) error {
+	return g.perform(ctx, methodGroupsLeave, groupID, groupUserFields{GroupID: groupID})
+}
+
+func (g Groups) Invite(groupID, userID int) error {
+	return g.InviteContext(context.Background(), groupID, userID)
+}
+
+// InviteContext invites friend to community
+func (g Groups) InviteContext(ctx context.Context, groupID, userID int) error {
+	return g.perform(ctx, methodGroupsInvite, groupID, groupUserFields{groupID, userID})
+}
+
+func (g Groups) ApproveRequest(groupID, userID int) error {
+	return g.ApproveRequestContext(context.Background(), groupID, userID)
+}
+
+// ApproveRequestContext approves request of user to join community
+func (g Groups) ApproveRequestContext(ctx context.Context, groupID, userID int) error {
+	return g.perform(ctx, methodGroupsApproveRequest, groupID, groupUserFields{groupID, userID})
+}
+
+func (g Groups) RemoveUser(groupID, userID int) error {
+	return g.RemoveUserContext(context.Background(), groupID, userID)
+}
+
+// RemoveUserContext removes user from community
+func (g Groups) RemoveUserContext(ctx context.Context, groupID, userID int) error {
+	return g.perform(ctx, methodGroupsRemoveUser, groupID, groupUserFields{groupID, userID})
+}
+
+type GroupRequestsFields struct {
+	GroupID int    `url:"group_id"`
+	Offset  int    `url:"offset,omitempty"`
+	Count   int    `url:"count,omitempty"`
+	Fields  string `url:"fields,omitempty"`
+}
+
+func (g Groups) GetRequests(fields GroupRequestsFields) (UsersResult, error) {
+	return g.GetRequestsContext(context.Background(), fields)
+}
+
+// GetRequestsContext returns page of users that requested to join
+// community, only ids are set if no fields are requested
+func (g Groups) GetRequestsContext(ctx context.Context, fields GroupRequestsFields) (result UsersResult, err error) {
+	err = g.DecodeContext(ctx, g.Request(methodGroupsGetRequests, fields), &result)
+	return result, groupPermissionError(methodGroupsGetRequests, fields.GroupID, err)
+}
+
+// BanReason is reason of ban in community
+type BanReason int
+
+const (
+	BanOther           BanReason = 0
+	BanSpam            BanReason = 1
+	BanVerbalAbuse     BanReason = 2
+	BanStrongLanguage  BanReason = 3
+	BanIrrelevantPosts BanReason = 4
+)
+
+type GroupBanFields struct {
+	GroupID int `url:"group_id"`
+	// OwnerID is id of user or negative id of community
+	OwnerID int `url:"owner_id"`
+	// EndDate of ban, zero for permanent ban
+	EndDate        time.Time `url:"end_date,unix,omitempty"`
+	Reason         BanReason `url:"reason,omitempty"`
+	Comment        string    `url:"comment,omitempty"`
+	CommentVisible Bool      `url:"comment_visible,omitempty"`
+}
+
+func (g Groups) Ban(fields GroupBanFields) error {
+	return g.BanContext(context.Background(), fields)
+}
+
+// BanContext adds user or community to community blacklist
+func (g Groups) BanContext(ctx context.Context, fields GroupBanFields) error {
+	return g.perform(ctx, methodGroupsBan, fields.GroupID, fields)
+}
+
+func (g Groups) Unban(groupID, ownerID int) error {
+	return g.UnbanContext(context.Background(), groupID, ownerID)
+}
+
+// UnbanContext removes user or community from community blacklist
+func (g Groups) UnbanContext(ctx context.Context, groupID, ownerID int) error {
+	fields := struct {
+		GroupID int `url:"group_id"`
+		OwnerID int `url:"owner_id"`
+	}{groupID, ownerID}
+	return g.perform(ctx, methodGroupsUnban, groupID, fields)
+}
+
+// BanInfo describes ban in community
+type BanInfo struct {
+	AdminID int
+	Date    time.Time
+	// EndDate of ban, zero for permanent ban
+	EndDate        time.Time
+	Reason         BanReason
+	Comment        string
+	CommentVisible bool
+}
+
+func (b *BanInfo) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		AdminID        int       `json:"admin_id"`
+		Date           int64     `json:"date"`
+		EndDate        int64     `json:"end_date"`
+		Reason         BanReason `json:"reason"`
+		Comment        string    `json:"comment"`
+		CommentVisible Bool      `json:"comment_visible"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*b = BanInfo{
+		AdminID:        raw.AdminID,
+		Date:           unixTime(raw.Date),
+		EndDate:        unixTime(raw.EndDate),
+		Reason:         raw.Reason,
+		Comment:        raw.Comment,
+		CommentVisible: bool(raw.CommentVisible),
+	}
+	return nil
+}
+
+// Banned is user or community in blacklist,
+// Type is "profile" for users and "group" for communities
+type Banned struct {
+	Type    string  `json:"type"`
+	User    User    `json:"profile"`
+	Group   Group   `json:"group"`
+	BanInfo BanInfo `json:"ban_info"`
+}
+
+// IsUser returns true if user is banned
+func (b Banned) IsUser() bool {
+	return b.Type == itemTypeProfile
+}
+
+type GroupBannedResult struct {
+	Count int      `json:"count"`
+	Items []Banned `json:"items"`
+}
+
+type GroupBannedFields struct {
+	GroupID int    `url:"group_id"`
+	Offset  int    `url:"offset,omitempty"`
+	Count   int    `url:"count,omitempty"`
+	Fields  string `url:"fields,omitempty"`
+	// OwnerID returns ban of single user or community
+	OwnerID int `url:"owner_id,omitempty"`
+}
+
+func (g Groups) GetBanned(fields GroupBannedFields) (GroupBannedResult, error) {
+	return g.GetBannedContext(context.Background(), fields)
+}
+
+// GetBannedContext returns page of community blacklist
+func (g Groups) GetBannedContext(ctx context.Context, fields GroupBannedFields) (result GroupBannedResult, err error) {
+	err = g.DecodeContext(ctx, g.Request(methodGroupsGetBanned, fields), &result)
+	return result, groupPermissionError(methodGroupsGetBanned, fields.GroupID, err)
+}
@@ -0,0 +1,115 @@
+package vk
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestModeration(t *testing.T) {
+	Convey("Moderation", t, func() {
+		mock := &requestsMock{responses: []string{`{"response": 1}`}}
+		g := Groups{record(mock, DefaultFactory)}
+
+		Convey("Membership", func() {
+			So(g.Join(1, true), ShouldBeNil)
+			So(g.Leave(1), ShouldBeNil)
+			So(g.Invite(1, 2), ShouldBeNil)
+			So(g.ApproveRequest(1, 3), ShouldBeNil)
+			So(g.RemoveUser(1, 4), ShouldBeNil)
+			So(g.Unban(1, -5), ShouldBeNil)
+			methods := []string{
+				methodGroupsJoin, methodGroupsLeave, methodGroupsInvite,
+				methodGroupsApproveRequest, methodGroupsRemoveUser, methodGroupsUnban,
+			}
+			So(mock.requests, ShouldHaveLength, len(methods))
+			for i, method := range methods {
+				So(mock.requests[i].Method, ShouldEqual, method)
+				So(mock.requests[i].Values.Get("group_id"), ShouldEqual, "1")
+			}
+			So(mock.requests[0].Values.Get("not_sure"), ShouldEqual, "1")
+			So(mock.requests[1].Values.Get("user_id"), ShouldBeBlank)
+			So(mock.requests[2].Values.Get("user_id"), ShouldEqual, "2")
+			So(mock.requests[4].Values.Get("user_id"), ShouldEqual, "4")
+			So(mock.requests[5].Values.Get("owner_id"), ShouldEqual, "-5")
+		})
+		Convey(methodGroupsBan, func() {
+			end := time.Unix(1600000000, 0)
+			So(g.Ban(GroupBanFields{
+				GroupID:        1,
+				OwnerID:        2,
+				EndDate:        end,
+				Reason:         BanSpam,
+				Comment:        "spam",
+				CommentVisible: true,
+			}), ShouldBeNil)
+			values := mock.requests[0].Values
+			So(values.Get("owner_id"), ShouldEqual, "2")
+			So(values.Get("end_date"), ShouldEqual, strconv.FormatInt(end.Unix(), 10))
+			So(values.Get("reason"), ShouldEqual, "1")
+			So(values.Get("comment"), ShouldEqual, "spam")
+			So(values.Get("comment_visible"), ShouldEqual, "1")
+
+			Convey("Permanent", func() {
+				So(g.Ban(GroupBanFields{GroupID: 1, OwnerID: 2}), ShouldBeNil)
+				values := mock.requests[1].Values
+				_, ok := values["end_date"]
+				So(ok, ShouldBeFalse)
+				So(values.Get("reason"), ShouldBeBlank)
+			})
+		})
+		Convey(methodGroupsGetBanned, func() {
+			mock.responses = []string{`{"response": {"count": 2, "items": [
+				{"type": "profile", "profile": {"id": 2, "first_name": "Ivan"},
+				 "ban_info": {"admin_id": 1, "date": 1500000000, "end_date": 0, "reason": 2, "comment": "abuse", "comment_visible": 1}},
+				{"type": "group", "group": {"id": 5, "name": "Spam"},
+				 "ban_info": {"admin_id": 1, "date": 1500000000, "end_date": 1600000000, "reason": 1}}
+			]}}`}
+			result, err := g.GetBanned(GroupBannedFields{GroupID: 1, Count: 2, Fields: "sex"})
+			So(err, ShouldBeNil)
+			So(result.Count, ShouldEqual, 2)
+			user := result.Items[0]
+			So(user.IsUser(), ShouldBeTrue)
+			So(user.User.FirstName, ShouldEqual, "Ivan")
+			So(user.BanInfo.Reason, ShouldEqual, BanVerbalAbuse)
+			So(user.BanInfo.Date, ShouldEqual, time.Unix(1500000000, 0))
+			So(user.BanInfo.EndDate.IsZero(), ShouldBeTrue)
+			So(user.BanInfo.CommentVisible, ShouldBeTrue)
+			group := result.Items[1]
+			So(group.IsUser(), ShouldBeFalse)
+			So(group.Group.Name, ShouldEqual, "Spam")
+			So(group.BanInfo.EndDate, ShouldEqual, time.Unix(1600000000, 0))
+			So(mock.requests[0].Values.Get("fields"), ShouldEqual, "sex")
+		})
+		Convey(methodGroupsGetRequests, func() {
+			mock.responses = []string{`{"response": {"count": 2, "items": [7, 8]}}`}
+			result, err := g.GetRequests(GroupRequestsFields{GroupID: 1, Offset: 10})
+			So(err, ShouldBeNil)
+			So(result.Items[1].ID, ShouldEqual, 8)
+			So(mock.requests[0].Values.Get("offset"), ShouldEqual, "10")
+		})
+		Convey("Permission errors", func() {
+			for _, code := range []ServerError{ErrGroupAccessProhibited, ErrNotAllowed} {
+				g := Groups{record(newApiMock("", Error{Code: code}), DefaultFactory)}
+				err := g.Ban(GroupBanFields{GroupID: 10, OwnerID: 2})
+				var permissionErr *PermissionError
+				So(errors.As(err, &permissionErr), ShouldBeTrue)
+				So(permissionErr.GroupID, ShouldEqual, 10)
+				So(permissionErr.Method, ShouldEqual, methodGroupsBan)
+				So(errors.Is(err, code), ShouldBeTrue)
+				So(IsPermissionError(err), ShouldBeTrue)
+				So(err.Error(), ShouldStartWith, "groups.ban: no permission in group 10")
+
+				_, err = g.GetBanned(GroupBannedFields{GroupID: 10})
+				So(errors.As(err, &permissionErr), ShouldBeTrue)
+			}
+			Convey("Other errors", func() {
+				g := Groups{record(newApiMock("", ErrAuthFailed), DefaultFactory)}
+				So(g.Leave(1), ShouldEqual, ErrAuthFailed)
+			})
+		})
+	})
+}
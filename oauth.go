@@ -0,0 +1,258 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tokenAccessToken = "access_token"
+	tokenExpiresIn   = "expires_in"
+	tokenUserID      = "user_id"
+	tokenEmail       = "email"
+	tokenError       = "error"
+	tokenErrorReason = "error_reason"
+	tokenErrorDesc   = "error_description"
+
+	// group tokens are returned as access_token_<group id>
+	tokenGroupPrefix = "access_token_"
+)
+
+// Token is access token issued by oauth server
+type Token struct {
+	AccessToken string `json:"access_token,omitempty"`
+	// ExpiresIn is token lifetime in seconds, zero for unlimited
+	ExpiresIn int64  `json:"expires_in"`
+	UserID    int    `json:"user_id,omitempty"`
+	Email     string `json:"email,omitempty"`
+	// Groups are community tokens, requested with Auth.GroupIDs
+	Groups []GroupToken `json:"groups,omitempty"`
+	// Expiry is time when token expires, zero for unlimited
+	Expiry time.Time `json:"expiry"`
+}
+
+// Expired returns true if token has limited lifetime that is over
+func (t Token) Expired() bool {
+	return !t.Expiry.IsZero() && !time.Now().Before(t.Expiry)
+}
+
+// GroupToken is access token of community
+type GroupToken struct {
+	GroupID     int    `json:"group_id"`
+	AccessToken string `json:"access_token"`
+}
+
+// OAuthError is error returned by oauth server
+type OAuthError struct {
+	Type        string `json:"error"`
+	Reason      string `json:"error_reason,omitempty"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e OAuthError) Error() string {
+	if len(e.Description) == 0 {
+		return fmt.Sprintf("oauth: %s", e.Type)
+	}
+	return fmt.Sprintf("oauth: %s: %s", e.Type, e.Description)
+}
+
+// Is returns true if target is OAuthError of same type,
+// so errors.Is(err, ErrOAuthInvalidGrant) can be used
+func (e OAuthError) Is(target error) bool {
+	t, ok := target.(OAuthError)
+	return ok && t.Type == e.Type
+}
+
+// ErrNoToken is returned when oauth response has no access token
+var ErrNoToken = errors.New("oauth: no access token in response")
+
+// Possible oauth errors
+var (
+	ErrOAuthInvalidRequest = OAuthError{Type: "invalid_request"}
+	ErrOAuthInvalidClient  = OAuthError{Type: "invalid_client"}
+	ErrOAuthInvalidGrant   = OAuthError{Type: "invalid_grant"}
+	ErrOAuthAccessDenied   = OAuthError{Type: "access_denied"}
+	ErrOAuthNeedValidation = OAuthError{Type: "need_validation"}
+	ErrOAuthNeedCaptcha    = OAuthError{Type: "need_captcha"}
+)
+
+// oauthURL returns url of oauth server with provided path
+func (a Auth) oauthURL(path string) url.URL {
+	u := url.URL{
+		Scheme: oauthScheme,
+		Host:   oauthHost,
+	}
+	if len(a.BaseURL) != 0 {
+		base, err := url.Parse(a.BaseURL)
+		// base url is provided by programmer, so
+		// invalid value is completely unexpected
+		must(err)
+		u = *base
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	return u
+}
+
+func (a Auth) httpClient() HTTPClient {
+	if a.HTTPClient == nil {
+		return defaultHTTPClient
+	}
+	return a.HTTPClient
+}
+
+// CodeURL returns redirect url for authorization code flow,
+// code from redirect is exchanged for token with Exchange
+func (a Auth) CodeURL() string {
+	a.ResponseType = oauthResponseCode
+	return a.URL()
+}
+
+// Exchange obtains access token for authorization code
+func (a Auth) Exchange(code, clientSecret string) (Token, error) {
+	return a.ExchangeContext(context.Background(), code, clientSecret)
+}
+
+// ExchangeContext obtains access token for authorization code
+func (a Auth) ExchangeContext(ctx context.Context, code, clientSecret string) (Token, error) {
+	if len(a.RedirectURI) == 0 {
+		a.RedirectURI = oauthRedirectURI
+	}
+	values := url.Values{}
+	values.Add(paramAppID, int64s(a.ID))
+	values.Add(paramClientSecret, clientSecret)
+	values.Add(paramRedirectURI, a.RedirectURI)
+	values.Add(paramCode, code)
+	return a.token(ctx, values)
+}
+
+// ServiceToken obtains service token of application with client
+// credentials flow. Service token allows requests to public data
+// that do not require user authorization.
+func (a Auth) ServiceToken(clientSecret string) (Token, error) {
+	return a.ServiceTokenContext(context.Background(), clientSecret)
+}
+
+// ServiceTokenContext obtains service token of application
+func (a Auth) ServiceTokenContext(ctx context.Context, clientSecret string) (Token, error) {
+	values := url.Values{}
+	values.Add(paramAppID, int64s(a.ID))
+	values.Add(paramClientSecret, clientSecret)
+	values.Add(paramGrantType, oauthGrantClient)
+	return a.token(ctx, values)
+}
+
+// token requests access token from oauth server
+func (a Auth) token(ctx context.Context, values url.Values) (Token, error) {
+	values.Set(paramVersion, defaultVersion)
+	u := a.oauthURL(oauthTokenPath)
+	u.RawQuery = values.Encode()
+	req, err := http.NewRequestWithContext(ctx, defaultMethod, u.String(), nil)
+	if err != nil {
+		return Token{}, err
+	}
+	res, err := a.httpClient().Do(req)
+	if err != nil {
+		// client secret is redacted from url like access token
+		secret := Request{Method: oauthTokenPath, Token: values.Get(paramClientSecret)}
+		return Token{}, newRequestError(secret, Attempt{Err: err}, err)
+	}
+	defer res.Body.Close()
+	// errors are returned with 4xx statuses
+	fields, err := decodeTokenFields(res.Body)
+	if err == nil && len(fields.Get(tokenError)) == 0 && res.StatusCode != http.StatusOK {
+		err = ErrBadResponseCode
+	}
+	if err != nil {
+		return Token{}, &RequestError{Method: oauthTokenPath, StatusCode: res.StatusCode, Err: err}
+	}
+	return parseToken(fields)
+}
+
+// decodeTokenFields reads JSON object of oauth response as strings
+func decodeTokenFields(r io.Reader) (url.Values, error) {
+	raw := make(map[string]json.RawMessage)
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	fields := url.Values{}
+	for k, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			// numbers are used as is
+			s = string(v)
+		}
+		fields.Set(k, s)
+	}
+	return fields, nil
+}
+
+// parseToken returns token or oauth error from response fields
+func parseToken(fields url.Values) (t Token, err error) {
+	if len(fields.Get(tokenError)) != 0 {
+		return t, OAuthError{
+			Type:        fields.Get(tokenError),
+			Reason:      fields.Get(tokenErrorReason),
+			Description: fields.Get(tokenErrorDesc),
+		}
+	}
+	t.AccessToken = fields.Get(tokenAccessToken)
+	t.Email = fields.Get(tokenEmail)
+	if v := fields.Get(tokenExpiresIn); len(v) != 0 {
+		if t.ExpiresIn, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return t, err
+		}
+	}
+	if t.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	if v := fields.Get(tokenUserID); len(v) != 0 {
+		if t.UserID, err = strconv.Atoi(v); err != nil {
+			return t, err
+		}
+	}
+	for k := range fields {
+		if !strings.HasPrefix(k, tokenGroupPrefix) {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(k, tokenGroupPrefix))
+		if err != nil {
+			return t, err
+		}
+		t.Groups = append(t.Groups, GroupToken{GroupID: id, AccessToken: fields.Get(k)})
+	}
+	sort.Slice(t.Groups, func(i, j int) bool { return t.Groups[i].GroupID < t.Groups[j].GroupID })
+	if len(t.AccessToken) == 0 && len(t.Groups) == 0 {
+		return t, ErrNoToken
+	}
+	return t, nil
+}
+
+// ParseRedirect returns token from url that user was redirected to
+// after implicit flow authorization, e.g.
+// https://oauth.vk.com/blank.html#access_token=...&expires_in=86400&user_id=1.
+// Authorization errors are returned as OAuthError.
+func ParseRedirect(rawurl string) (Token, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return Token{}, err
+	}
+	// errors can be passed in query instead of fragment
+	fields := u.Query()
+	fragment, err := url.ParseQuery(u.Fragment)
+	if err != nil {
+		return Token{}, err
+	}
+	for k, v := range fragment {
+		fields[k] = v
+	}
+	return parseToken(fields)
+}
@@ -0,0 +1,207 @@
+package vk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOAuthExchange(t *testing.T) {
+	Convey("Authorization code flow", t, func() {
+		var query url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			if r.URL.Path != oauthTokenPath {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			switch query.Get(paramCode) {
+			case "user":
+				w.Write([]byte(`{"access_token":"533bacf01e1165b57531ad114461ae8736d6506a3",
+					"expires_in":43200,"user_id":66748,"email":"user@example.com"}`))
+			case "groups":
+				w.Write([]byte(`{"access_token_59800369":"533bacf01e1165b57531ad114461ae8736d6506a3",
+					"access_token_41126":"7a6fa4dff77a228eeda56603b8f53806c883f011c40b72630bb50df056f6479e52a",
+					"expires_in":0}`))
+			case "empty":
+				w.Write([]byte(`{"expires_in":0}`))
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"invalid_grant","error_description":"Code is invalid or expired."}`))
+			}
+		}))
+		defer server.Close()
+		auth := Auth{ID: 42, BaseURL: server.URL, RedirectURI: "http://localhost/callback"}
+
+		Convey("Code url", func() {
+			u, err := url.Parse(auth.CodeURL())
+			So(err, ShouldBeNil)
+			So(u.Host, ShouldEqual, server.Listener.Addr().String())
+			So(u.Path, ShouldEqual, oauthPath)
+			So(u.Query().Get(paramResponseType), ShouldEqual, "code")
+			So(u.Query()[paramState], ShouldBeEmpty)
+			auth.State = "random"
+			u, err = url.Parse(auth.CodeURL())
+			So(err, ShouldBeNil)
+			So(u.Query().Get(paramState), ShouldEqual, "random")
+			u, err = url.Parse(Auth{GroupIDs: []int{1, 2}}.CodeURL())
+			So(err, ShouldBeNil)
+			So(u.Host, ShouldEqual, oauthHost)
+			So(u.Query().Get(paramGroupIDs), ShouldEqual, "1,2")
+		})
+		Convey("User token", func() {
+			token, err := auth.Exchange("user", "secret")
+			So(err, ShouldBeNil)
+			So(query.Get(paramAppID), ShouldEqual, "42")
+			So(query.Get(paramClientSecret), ShouldEqual, "secret")
+			So(query.Get(paramRedirectURI), ShouldEqual, "http://localhost/callback")
+			So(token.AccessToken, ShouldEqual, "533bacf01e1165b57531ad114461ae8736d6506a3")
+			So(token.ExpiresIn, ShouldEqual, 43200)
+			So(token.UserID, ShouldEqual, 66748)
+			So(token.Email, ShouldEqual, "user@example.com")
+		})
+		Convey("Group tokens", func() {
+			token, err := auth.Exchange("groups", "secret")
+			So(err, ShouldBeNil)
+			So(token.AccessToken, ShouldBeBlank)
+			So(token.Groups, ShouldResemble, []GroupToken{
+				{41126, "7a6fa4dff77a228eeda56603b8f53806c883f011c40b72630bb50df056f6479e52a"},
+				{59800369, "533bacf01e1165b57531ad114461ae8736d6506a3"},
+			})
+		})
+		Convey("No token", func() {
+			_, err := auth.Exchange("empty", "secret")
+			So(err, ShouldEqual, ErrNoToken)
+		})
+		Convey("Error", func() {
+			_, err := auth.Exchange("invalid", "secret")
+			So(errors.Is(err, ErrOAuthInvalidGrant), ShouldBeTrue)
+			So(errors.Is(err, ErrOAuthInvalidClient), ShouldBeFalse)
+			var oauthErr OAuthError
+			So(errors.As(err, &oauthErr), ShouldBeTrue)
+			So(oauthErr.Description, ShouldEqual, "Code is invalid or expired.")
+			So(err.Error(), ShouldEqual, "oauth: invalid_grant: Code is invalid or expired.")
+		})
+		Convey("Bad response", func() {
+			auth.BaseURL = server.URL + "/unknown"
+			_, err := auth.Exchange("user", "secret")
+			So(errors.Is(err, ErrBadResponseCode), ShouldBeFalse)
+			var requestErr *RequestError
+			So(errors.As(err, &requestErr), ShouldBeTrue)
+			So(requestErr.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
+		Convey("Transport error", func() {
+			auth.BaseURL = "http://127.0.0.1:0"
+			_, err := auth.Exchange("user", "secret")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldNotContainSubstring, "secret")
+		})
+	})
+}
+
+func TestServiceToken(t *testing.T) {
+	Convey("Client credentials flow", t, func() {
+		var queries []url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			queries = append(queries, query)
+			switch {
+			case r.URL.Path == oauthTokenPath && query.Get(paramClientSecret) == "secret":
+				w.Write([]byte(`{"access_token":"service","expires_in":0}`))
+			case r.URL.Path == oauthTokenPath:
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"invalid_client","error_description":"client_secret is incorrect"}`))
+			default:
+				w.Write([]byte(`{"response": {"count": 0, "items": []}}`))
+			}
+		}))
+		defer server.Close()
+		auth := Auth{ID: 42, BaseURL: server.URL}
+
+		Convey("Token", func() {
+			token, err := auth.ServiceToken("secret")
+			So(err, ShouldBeNil)
+			So(token.AccessToken, ShouldEqual, "service")
+			So(queries[0].Get(paramGrantType), ShouldEqual, "client_credentials")
+			So(queries[0].Get(paramAppID), ShouldEqual, "42")
+		})
+		Convey("Client", func() {
+			client, err := NewWithServiceToken(context.Background(), auth, "secret",
+				WithBaseURL(server.URL+"/method/"), WithLimiter(nil))
+			So(err, ShouldBeNil)
+			_, err = client.Video.Get(VideoGetFields{})
+			So(err, ShouldBeNil)
+			_, err = client.Groups.Get(GroupGetFields{})
+			So(err, ShouldBeNil)
+			So(queries, ShouldHaveLength, 3)
+			So(queries[1].Get(paramToken), ShouldEqual, "service")
+			So(queries[2].Get(paramToken), ShouldEqual, "service")
+		})
+		Convey("Service rate", func() {
+			client, err := NewWithServiceToken(context.Background(), auth, "secret")
+			So(err, ShouldBeNil)
+			So(client.Limiter().(*RateLimiter).rateOf("service"), ShouldResemble, newRate(minimumServiceRate, 1))
+		})
+		Convey("Invalid secret", func() {
+			client, err := NewWithServiceToken(context.Background(), auth, "invalid")
+			So(client, ShouldBeNil)
+			So(errors.Is(err, ErrOAuthInvalidClient), ShouldBeTrue)
+		})
+	})
+}
+
+func TestParseRedirect(t *testing.T) {
+	Convey("Implicit flow redirect", t, func() {
+		Convey("User token", func() {
+			start := time.Now()
+			token, err := ParseRedirect("https://oauth.vk.com/blank.html#access_token=533bacf01e11f55b536a565b57531ad114461ae8736d6506a3&expires_in=86400&user_id=8492&email=user@example.com")
+			So(err, ShouldBeNil)
+			So(token.AccessToken, ShouldEqual, "533bacf01e11f55b536a565b57531ad114461ae8736d6506a3")
+			So(token.UserID, ShouldEqual, 8492)
+			So(token.Email, ShouldEqual, "user@example.com")
+			So(token.ExpiresIn, ShouldEqual, 86400)
+			So(token.Expiry, ShouldHappenOnOrBetween, start.Add(time.Hour*24), time.Now().Add(time.Hour*24))
+			So(token.Expired(), ShouldBeFalse)
+		})
+		Convey("Unlimited token", func() {
+			token, err := ParseRedirect("https://oauth.vk.com/blank.html#access_token=abc&expires_in=0&user_id=1")
+			So(err, ShouldBeNil)
+			So(token.Expiry.IsZero(), ShouldBeTrue)
+			So(token.Expired(), ShouldBeFalse)
+		})
+		Convey("Group tokens", func() {
+			token, err := ParseRedirect("https://oauth.vk.com/blank.html#access_token_1=first&access_token_2=second&expires_in=0")
+			So(err, ShouldBeNil)
+			So(token.Groups, ShouldResemble, []GroupToken{{1, "first"}, {2, "second"}})
+		})
+		Convey("Error", func() {
+			_, err := ParseRedirect("https://oauth.vk.com/blank.html#error=access_denied&error_reason=user_denied&error_description=User%20denied%20your%20request")
+			So(errors.Is(err, ErrOAuthAccessDenied), ShouldBeTrue)
+			var oauthErr OAuthError
+			So(errors.As(err, &oauthErr), ShouldBeTrue)
+			So(oauthErr.Reason, ShouldEqual, "user_denied")
+			So(oauthErr.Description, ShouldEqual, "User denied your request")
+		})
+		Convey("Error in query", func() {
+			_, err := ParseRedirect("http://localhost/callback?error=access_denied&error_reason=user_denied")
+			So(errors.Is(err, ErrOAuthAccessDenied), ShouldBeTrue)
+		})
+		Convey("Invalid", func() {
+			_, err := ParseRedirect("https://oauth.vk.com/blank.html")
+			So(err, ShouldEqual, ErrNoToken)
+			_, err = ParseRedirect("https://oauth.vk.com/blank.html#user_id=abc&access_token=1")
+			So(err, ShouldNotBeNil)
+			_, err = ParseRedirect("://")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Expired", func() {
+			So(Token{Expiry: time.Now().Add(-time.Second)}.Expired(), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,91 @@
+package vk
+
+import "net/url"
+
+// Option configures Client on creation
+type Option func(c *Client)
+
+// WithBaseURL sets base url of api methods, e.g. "https://api.vk.com/method/",
+// method name is appended to its path. Panics on invalid url.
+func WithBaseURL(rawurl string) Option {
+	u, err := url.Parse(rawurl)
+	must(err)
+	return func(c *Client) {
+		c.endpoint.URL = *u
+	}
+}
+
+// WithVersion sets api version that is sent with every request
+func WithVersion(version string) Option {
+	return func(c *Client) {
+		c.endpoint.Version = version
+	}
+}
+
+// WithLanguage sets lang parameter of every request,
+// unless request provides it explicitly
+func WithLanguage(lang string) Option {
+	return func(c *Client) {
+		c.endpoint.Lang = lang
+	}
+}
+
+// WithTestMode enables test_mode parameter that allows
+// to send requests from application in development
+func WithTestMode(enabled bool) Option {
+	return func(c *Client) {
+		c.endpoint.TestMode = enabled
+	}
+}
+
+// WithPOST makes client send requests as url encoded form in body of
+// POST request when encoded parameters are longer than threshold bytes.
+// Zero threshold sends every request as POST, keeping access token out
+// of urls; negative threshold always uses GET.
+func WithPOST(threshold int) Option {
+	return func(c *Client) {
+		c.endpoint.PostThreshold = threshold
+	}
+}
+
+// WithHTTPClient sets underlying http client
+func WithHTTPClient(httpClient HTTPClient) Option {
+	return func(c *Client) {
+		c.SetHTTPClient(httpClient)
+	}
+}
+
+// WithRetryPolicy sets policy of repeating failed requests, nil disables retries
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.SetRetryPolicy(policy)
+	}
+}
+
+// WithCaptchaSolver sets solver that is invoked on ErrCaptchaNeeded
+func WithCaptchaSolver(solver CaptchaSolver) Option {
+	return func(c *Client) {
+		c.SetCaptchaSolver(solver)
+	}
+}
+
+// WithLogger sets logger of client events, nil disables logging
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		c.SetLogger(logger)
+	}
+}
+
+// WithLimiter sets request rate limiter, nil disables limiting
+func WithLimiter(limiter Limiter) Option {
+	return func(c *Client) {
+		c.SetLimiter(limiter)
+	}
+}
+
+// WithTokenKind sets kind of access token for rate limiting
+func WithTokenKind(token string, kind TokenKind) Option {
+	return func(c *Client) {
+		c.SetTokenKind(token, kind)
+	}
+}
@@ -0,0 +1,194 @@
+package vk
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimitBench = time.Second * 10
+	defaultAuthBench      = time.Hour
+	// visibleTokenPrefix is length of token prefix shown in health report
+	visibleTokenPrefix = 4
+)
+
+// TokenPool is RequestFactory that distributes requests between
+// many access tokens in round-robin order. Tokens that fail with
+// rate limit or authorization errors are benched for a while.
+// Client limits rate of every token separately, so pool with n
+// tokens performs up to n times more requests per second.
+// It is safe for concurrent use.
+type TokenPool struct {
+	mux            sync.Mutex
+	tokens         []*pooledToken
+	next           int
+	rateLimitBench time.Duration
+	authBench      time.Duration
+	kind           TokenKind
+}
+
+type pooledToken struct {
+	token    string
+	until    time.Time
+	requests int
+	failures int
+	lastErr  error
+}
+
+// TokenHealth is state of token in pool
+type TokenHealth struct {
+	// Index of token in pool
+	Index int
+	// Token prefix, full token is not exposed
+	Token string
+	// Available is false if token is benched
+	Available    bool
+	BenchedUntil time.Time
+	Requests     int
+	Failures     int
+	LastError    error
+}
+
+// NewTokenPool returns pool of provided user tokens
+func NewTokenPool(tokens ...string) *TokenPool {
+	return NewTokenPoolOf(TokenUser, tokens...)
+}
+
+// NewTokenPoolOf returns pool of provided tokens of kind,
+// so client limits them with rate of that kind
+func NewTokenPoolOf(kind TokenKind, tokens ...string) *TokenPool {
+	p := &TokenPool{
+		rateLimitBench: defaultRateLimitBench,
+		authBench:      defaultAuthBench,
+		kind:           kind,
+	}
+	for _, token := range tokens {
+		p.tokens = append(p.tokens, &pooledToken{token: token})
+	}
+	return p
+}
+
+// SetBench sets durations for which tokens are benched
+// after rate limit and authorization errors
+func (p *TokenPool) SetBench(rateLimit, auth time.Duration) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.rateLimitBench = rateLimit
+	p.authBench = auth
+}
+
+// acquire returns next available token. If all tokens are
+// benched, token that will be available first is returned.
+func (p *TokenPool) acquire() string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	now := time.Now()
+	var earliest *pooledToken
+	for i := 0; i < len(p.tokens); i++ {
+		t := p.tokens[(p.next+i)%len(p.tokens)]
+		if !now.Before(t.until) {
+			p.next = (p.next + i + 1) % len(p.tokens)
+			t.requests++
+			return t.token
+		}
+		if earliest == nil || t.until.Before(earliest.until) {
+			earliest = t
+		}
+	}
+	earliest.requests++
+	return earliest.token
+}
+
+// Request implements RequestFactory
+func (p *TokenPool) Request(method string, arguments interface{}) Request {
+	return Factory{p.acquire()}.Request(method, arguments)
+}
+
+// Report records result of request performed with token
+func (p *TokenPool) Report(token string, err error) {
+	if err == nil {
+		return
+	}
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	for _, t := range p.tokens {
+		if t.token != token {
+			continue
+		}
+		t.failures++
+		t.lastErr = err
+		switch {
+		case IsRateLimit(err):
+			t.until = time.Now().Add(p.rateLimitBench)
+		case IsAuthError(err):
+			t.until = time.Now().Add(p.authBench)
+		}
+	}
+}
+
+// replace benches token that failed with rate limit or authorization
+// error and returns another available token. It returns false if
+// err does not bench token, token is not from pool or there is no
+// other available token.
+func (p *TokenPool) replace(token string, err error) (string, bool) {
+	if !IsRateLimit(err) && !IsAuthError(err) {
+		return "", false
+	}
+	p.mux.Lock()
+	now := time.Now()
+	own, other := false, false
+	for _, t := range p.tokens {
+		if t.token == token {
+			own = true
+			continue
+		}
+		if !now.Before(t.until) {
+			other = true
+		}
+	}
+	p.mux.Unlock()
+	if !own || !other {
+		return "", false
+	}
+	p.Report(token, err)
+	return p.acquire(), true
+}
+
+// Health returns state of every token in pool
+func (p *TokenPool) Health() []TokenHealth {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	now := time.Now()
+	health := make([]TokenHealth, len(p.tokens))
+	for i, t := range p.tokens {
+		prefix := t.token
+		if len(prefix) > visibleTokenPrefix {
+			prefix = prefix[:visibleTokenPrefix]
+		}
+		health[i] = TokenHealth{
+			Index:     i,
+			Token:     prefix,
+			Available: !now.Before(t.until),
+			Requests:  t.requests,
+			Failures:  t.failures,
+			LastError: t.lastErr,
+		}
+		if !health[i].Available {
+			health[i].BenchedUntil = t.until
+		}
+	}
+	return health
+}
+
+// Available returns count of tokens that are not benched
+func (p *TokenPool) Available() (n int) {
+	for _, h := range p.Health() {
+		if h.Available {
+			n++
+		}
+	}
+	return n
+}
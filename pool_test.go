@@ -0,0 +1,140 @@
+package vk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTokenPool(t *testing.T) {
+	Convey("Token pool", t, func() {
+		pool := NewTokenPool("first", "second", "third")
+		next := func() string {
+			return pool.Request("users.get", nil).Token
+		}
+
+		Convey("Round robin", func() {
+			So([]string{next(), next(), next(), next()}, ShouldResemble, []string{"first", "second", "third", "first"})
+		})
+		Convey("Rate limited token is benched", func() {
+			pool.SetBench(time.Hour, time.Hour)
+			pool.Report("second", Error{Code: ErrTooManyRequests})
+			So([]string{next(), next(), next()}, ShouldResemble, []string{"first", "third", "first"})
+			So(pool.Available(), ShouldEqual, 2)
+			health := pool.Health()
+			So(health[1].Available, ShouldBeFalse)
+			So(health[1].Failures, ShouldEqual, 1)
+			So(health[1].Token, ShouldEqual, "seco")
+			So(ErrTooManyRequests.Is(health[1].LastError), ShouldBeTrue)
+			So(health[1].BenchedUntil, ShouldHappenAfter, time.Now())
+			So(health[0].Requests, ShouldEqual, 2)
+		})
+		Convey("Bench ends", func() {
+			pool.SetBench(time.Millisecond, time.Hour)
+			pool.Report("second", Error{Code: ErrTooManyRequests})
+			time.Sleep(time.Millisecond * 2)
+			So(pool.Available(), ShouldEqual, 3)
+		})
+		Convey("Auth error", func() {
+			pool.SetBench(time.Millisecond, time.Hour)
+			pool.Report("first", Error{Code: ErrAuthFailed})
+			time.Sleep(time.Millisecond * 2)
+			So(pool.Available(), ShouldEqual, 2)
+		})
+		Convey("Other errors do not bench", func() {
+			pool.Report("first", Error{Code: ErrNotAllowed})
+			So(pool.Available(), ShouldEqual, 3)
+			So(pool.Health()[0].Failures, ShouldEqual, 1)
+		})
+		Convey("All benched", func() {
+			pool.SetBench(time.Hour, time.Hour*2)
+			pool.Report("first", Error{Code: ErrAuthFailed})
+			pool.Report("second", Error{Code: ErrTooManyRequests})
+			pool.Report("third", Error{Code: ErrAuthFailed})
+			So(pool.Available(), ShouldEqual, 0)
+			So(next(), ShouldEqual, "second")
+		})
+		Convey("Empty", func() {
+			So(NewTokenPool().Request("users.get", nil).Token, ShouldBeBlank)
+		})
+	})
+	Convey("Client with pool", t, func() {
+		var (
+			mux  sync.Mutex
+			used = make(map[string]int)
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.URL.Query().Get(paramToken)
+			mux.Lock()
+			used[token]++
+			mux.Unlock()
+			if token == "limited" {
+				fmt.Fprintf(w, `{"error": {"error_code": %d, "error_msg": "Too many requests per second"}}`, ErrTooManyRequests)
+				return
+			}
+			w.Write([]byte(`{"response": {"count": 0, "items": []}}`))
+		}))
+		defer server.Close()
+
+		pool := NewTokenPool("first", "limited", "second")
+		pool.SetBench(time.Hour, time.Hour)
+		client := NewWithPool(pool, WithBaseURL(server.URL), WithLimiter(nil), WithRetryPolicy(nil))
+
+		wg := new(sync.WaitGroup)
+		for i := 0; i < 30; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				client.Groups.Get(GroupGetFields{})
+			}()
+		}
+		wg.Wait()
+		So(pool.Health()[1].Available, ShouldBeFalse)
+		limited := used["limited"]
+		So(limited, ShouldBeGreaterThan, 0)
+
+		for i := 0; i < 10; i++ {
+			_, err := client.Groups.Get(GroupGetFields{})
+			So(err, ShouldBeNil)
+		}
+		So(used["limited"], ShouldEqual, limited)
+		So(used["first"]+used["second"], ShouldEqual, 40-limited)
+
+		Convey("Rate limited request is repeated with other token", func() {
+			used = make(map[string]int)
+			pool := NewTokenPool("limited", "first")
+			pool.SetBench(time.Hour, time.Hour)
+			client := NewWithPool(pool,
+				WithBaseURL(server.URL),
+				WithLimiter(nil),
+				WithRetryPolicy(Backoff{Attempts: 2, Base: time.Hour, Max: time.Hour}),
+			)
+			for i := 0; i < 3; i++ {
+				_, err := client.Groups.Get(GroupGetFields{})
+				So(err, ShouldBeNil)
+			}
+			So(used, ShouldResemble, map[string]int{"limited": 1, "first": 3})
+			health := pool.Health()
+			So(health[0].Available, ShouldBeFalse)
+			So(health[0].Failures, ShouldEqual, 1)
+			So(health[1].Failures, ShouldEqual, 0)
+		})
+		Convey("Other tokens are not replaced", func() {
+			used = make(map[string]int)
+			pool := NewTokenPool("first")
+			client := NewWithPool(pool,
+				WithBaseURL(server.URL),
+				WithLimiter(nil),
+				WithRetryPolicy(Backoff{Attempts: 2, Base: time.Millisecond, Max: time.Millisecond}),
+			)
+			_, err := client.Do(Request{Method: "groups.get", Token: "limited"})
+			So(ErrTooManyRequests.Is(err), ShouldBeTrue)
+			So(used, ShouldResemble, map[string]int{"limited": 2})
+		})
+	})
+}
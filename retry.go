@@ -0,0 +1,112 @@
+package vk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultRetryAttempts = 5
+	defaultRetryBase     = time.Second / 2
+	defaultRetryMax      = time.Second * 10
+)
+
+// Attempt describes failed attempt of request
+type Attempt struct {
+	// Number of attempt, starting from 1
+	Number int
+	// Err is transport or response decoding error
+	Err error
+	// StatusCode of http response, zero on transport error
+	StatusCode int
+	// Code is vk error code, ErrZero if server returned no error
+	Code ServerError
+}
+
+// RetryPolicy decides whether failed request should be repeated
+type RetryPolicy interface {
+	// Retry returns delay before next attempt,
+	// or false if request should not be repeated
+	Retry(attempt Attempt) (time.Duration, bool)
+}
+
+// NoRetry is RetryPolicy that never repeats requests
+type NoRetry struct{}
+
+// Retry always returns false
+func (NoRetry) Retry(attempt Attempt) (time.Duration, bool) {
+	return 0, false
+}
+
+// Backoff is RetryPolicy that repeats transport errors, server side
+// http errors and temporary vk errors with exponentially growing delay.
+// Delay is randomized between half and full value to spread retries
+// of concurrent callers.
+type Backoff struct {
+	// Attempts is maximum total count of attempts
+	Attempts int
+	// Base is delay after first attempt
+	Base time.Duration
+	// Max is upper bound of delay
+	Max time.Duration
+}
+
+// DefaultRetryPolicy is used by clients created with New and NewWithToken
+var DefaultRetryPolicy RetryPolicy = Backoff{
+	Attempts: defaultRetryAttempts,
+	Base:     defaultRetryBase,
+	Max:      defaultRetryMax,
+}
+
+// Retry implements RetryPolicy
+func (b Backoff) Retry(attempt Attempt) (time.Duration, bool) {
+	if attempt.Number >= b.Attempts || !isTemporary(attempt) {
+		return 0, false
+	}
+	delay := b.Base << uint(attempt.Number-1)
+	if delay > b.Max || delay <= 0 {
+		delay = b.Max
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1)), true
+}
+
+// isTemporary returns true if attempt failed
+// due to error that can go away with time
+func isTemporary(attempt Attempt) bool {
+	if attempt.Err != nil {
+		return isTransportError(attempt.Err)
+	}
+	if attempt.StatusCode >= http.StatusInternalServerError ||
+		attempt.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return attempt.Code.Retryable()
+}
+
+// isTransportError returns true if err is caused by network failure,
+// like timeout, reset connection or truncated response body.
+// Malformed responses, invalid requests and cancelled contexts
+// are not repeated.
+func isTransportError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
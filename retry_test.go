@@ -0,0 +1,140 @@
+package vk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBackoff(t *testing.T) {
+	Convey("Backoff", t, func() {
+		b := Backoff{Attempts: 5, Base: time.Second, Max: time.Second * 5}
+		Convey("Delay grows exponentially with jitter", func() {
+			for _, v := range []struct {
+				number   int
+				min, max time.Duration
+			}{
+				{1, time.Second / 2, time.Second},
+				{2, time.Second, time.Second * 2},
+				{3, time.Second * 2, time.Second * 4},
+				{4, time.Second * 5 / 2, time.Second * 5},
+			} {
+				delay, ok := b.Retry(Attempt{Number: v.number, Err: io.ErrUnexpectedEOF})
+				So(ok, ShouldBeTrue)
+				So(delay, ShouldBeBetweenOrEqual, v.min, v.max)
+			}
+		})
+		Convey("Attempts are limited", func() {
+			_, ok := b.Retry(Attempt{Number: 5, Err: io.ErrUnexpectedEOF})
+			So(ok, ShouldBeFalse)
+		})
+		Convey("Only temporary errors are repeated", func() {
+			for _, v := range []struct {
+				attempt Attempt
+				retry   bool
+			}{
+				{Attempt{Err: io.ErrUnexpectedEOF}, true},
+				{Attempt{Err: &url.Error{Op: "Get", URL: "https://api.vk.com", Err: io.EOF}}, true},
+				{Attempt{Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}, true},
+				{Attempt{Err: &net.DNSError{Err: "timeout", IsTimeout: true}}, true},
+				{Attempt{Err: &url.Error{Op: "Get", URL: "ftp://api.vk.com", Err: errors.New("unsupported protocol scheme")}}, false},
+				{Attempt{Err: &json.SyntaxError{}}, false},
+				{Attempt{Err: &json.UnmarshalTypeError{}}, false},
+				{Attempt{StatusCode: http.StatusBadGateway, Code: ErrBadResponseCode}, true},
+				{Attempt{StatusCode: http.StatusTooManyRequests, Code: ErrBadResponseCode}, true},
+				{Attempt{StatusCode: http.StatusBadRequest, Code: ErrBadResponseCode}, false},
+				{Attempt{StatusCode: http.StatusOK, Code: ErrTooManyRequests}, true},
+				{Attempt{StatusCode: http.StatusOK, Code: ErrInternalServerError}, true},
+				{Attempt{StatusCode: http.StatusOK, Code: ErrAuthFailed}, false},
+				{Attempt{StatusCode: http.StatusOK}, false},
+			} {
+				v.attempt.Number = 1
+				_, ok := b.Retry(v.attempt)
+				So(ok, ShouldEqual, v.retry)
+			}
+		})
+	})
+	Convey("No retry", t, func() {
+		_, ok := NoRetry{}.Retry(Attempt{Number: 1, Err: io.ErrUnexpectedEOF})
+		So(ok, ShouldBeFalse)
+	})
+}
+
+func TestClientRetry(t *testing.T) {
+	Convey("Client retry", t, func() {
+		var calls int32
+		failures := []func(w http.ResponseWriter){
+			func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			func(w http.ResponseWriter) {
+				fmt.Fprintf(w, `{"error": {"error_code": %d, "error_msg": "Too many requests per second"}}`, ErrTooManyRequests)
+			},
+			func(w http.ResponseWriter) {
+				fmt.Fprintf(w, `{"error": {"error_code": %d, "error_msg": "Internal server error"}}`, ErrInternalServerError)
+			},
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := int(atomic.AddInt32(&calls, 1))
+			if n <= len(failures) {
+				failures[n-1](w)
+				return
+			}
+			w.Write([]byte(`{"response": 1}`))
+		}))
+		defer server.Close()
+
+		Convey("Temporary errors are repeated", func() {
+			client := New(
+				WithBaseURL(server.URL),
+				WithLimiter(nil),
+				WithRetryPolicy(Backoff{Attempts: 5, Base: time.Millisecond, Max: time.Millisecond * 10}),
+			)
+			res, err := client.Do(Request{Method: "users.get"})
+			So(err, ShouldBeNil)
+			So(res.Response.String(), ShouldEqual, "1")
+			So(atomic.LoadInt32(&calls), ShouldEqual, 4)
+		})
+		Convey("Attempts are limited", func() {
+			client := New(
+				WithBaseURL(server.URL),
+				WithLimiter(nil),
+				WithRetryPolicy(Backoff{Attempts: 2, Base: time.Millisecond, Max: time.Millisecond * 10}),
+			)
+			_, err := client.Do(Request{Method: "users.get"})
+			So(ErrTooManyRequests.Is(err), ShouldBeTrue)
+			So(atomic.LoadInt32(&calls), ShouldEqual, 2)
+		})
+		Convey("Malformed response is not repeated", func() {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.Write([]byte(`{"response": not json}`))
+			}))
+			defer server.Close()
+			client := New(
+				WithBaseURL(server.URL),
+				WithLimiter(nil),
+				WithRetryPolicy(Backoff{Attempts: 5, Base: time.Millisecond, Max: time.Millisecond * 10}),
+			)
+			_, err := client.Do(Request{Method: "users.get"})
+			So(err, ShouldNotBeNil)
+			So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+		})
+		Convey("No retry", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil), WithRetryPolicy(nil))
+			_, err := client.Do(Request{Method: "users.get"})
+			So(errors.Is(err, ErrBadResponseCode), ShouldBeTrue)
+			So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+		})
+	})
+}
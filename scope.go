@@ -2,17 +2,75 @@ package vk
 
 import "strings"
 import "sort"
+import "strconv"
 
 // Permission of application
 type Permission string
 
+// Permissions of user token
+// https://vk.com/dev/permissions
 const (
-	PermOffline Permission = "offline"
-	PermFriends Permission = "friends"
-	PermPhotos  Permission = "photos"
-	PermGroups  Permission = "groups"
+	PermNotify        Permission = "notify"
+	PermFriends       Permission = "friends"
+	PermPhotos        Permission = "photos"
+	PermAudio         Permission = "audio"
+	PermVideo         Permission = "video"
+	PermStories       Permission = "stories"
+	PermPages         Permission = "pages"
+	PermStatus        Permission = "status"
+	PermNotes         Permission = "notes"
+	PermMessages      Permission = "messages"
+	PermWall          Permission = "wall"
+	PermAds           Permission = "ads"
+	PermOffline       Permission = "offline"
+	PermDocs          Permission = "docs"
+	PermGroups        Permission = "groups"
+	PermNotifications Permission = "notifications"
+	PermStats         Permission = "stats"
+	PermEmail         Permission = "email"
+	PermMarket        Permission = "market"
 )
 
+// Permissions of community token,
+// PermStories, PermPhotos, PermMessages and PermDocs are also used
+const (
+	PermManage    Permission = "manage"
+	PermAppWidget Permission = "app_widget"
+)
+
+// userMask are bits of user permissions
+var userMask = map[Permission]int{
+	PermNotify:        1,
+	PermFriends:       2,
+	PermPhotos:        4,
+	PermAudio:         8,
+	PermVideo:         16,
+	PermStories:       64,
+	PermPages:         128,
+	PermStatus:        1024,
+	PermNotes:         2048,
+	PermMessages:      4096,
+	PermWall:          8192,
+	PermAds:           32768,
+	PermOffline:       65536,
+	PermDocs:          131072,
+	PermGroups:        262144,
+	PermNotifications: 524288,
+	PermStats:         1048576,
+	PermEmail:         4194304,
+	PermMarket:        134217728,
+}
+
+// groupMask are bits of community permissions
+var groupMask = map[Permission]int{
+	PermStories:   1,
+	PermPhotos:    4,
+	PermAppWidget: 64,
+	PermMessages:  4096,
+	PermDocs:      131072,
+	PermManage:    262144,
+}
+
 func (p Permission) String() string {
 	return string(p)
 }
@@ -52,3 +110,60 @@ func NewScope(permissions ...Permission) Scope {
 	s.Add(permissions...)
 	return s
 }
+
+// mask returns bitmask of scope, permissions without bit are ignored
+func (s Scope) mask(bits map[Permission]int) (mask int) {
+	for p, ok := range s {
+		if ok {
+			mask |= bits[p]
+		}
+	}
+	return mask
+}
+
+// Mask returns bitmask of user permissions,
+// that can be passed as scope instead of list
+func (s Scope) Mask() int {
+	return s.mask(userMask)
+}
+
+// GroupMask returns bitmask of community permissions
+func (s Scope) GroupMask() int {
+	return s.mask(groupMask)
+}
+
+func scopeFromMask(mask int, bits map[Permission]int) Scope {
+	s := Scope{}
+	for p, bit := range bits {
+		if mask&bit != 0 {
+			s.Add(p)
+		}
+	}
+	return s
+}
+
+// ScopeFromMask returns user permissions from bitmask,
+// e.g. result of account.getAppPermissions
+func ScopeFromMask(mask int) Scope {
+	return scopeFromMask(mask, userMask)
+}
+
+// GroupScopeFromMask returns community permissions from bitmask
+func GroupScopeFromMask(mask int) Scope {
+	return scopeFromMask(mask, groupMask)
+}
+
+// ParseScope returns scope from comma separated list of permissions,
+// e.g. "friends,offline", or from user permissions bitmask
+func ParseScope(value string) Scope {
+	if mask, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return ScopeFromMask(mask)
+	}
+	s := Scope{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); len(v) != 0 {
+			s.Add(Permission(v))
+		}
+	}
+	return s
+}
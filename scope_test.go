@@ -38,3 +38,40 @@ func TestScope(t *testing.T) {
 		})
 	})
 }
+
+func TestScopeMask(t *testing.T) {
+	Convey("Bitmask", t, func() {
+		Convey("User", func() {
+			s := NewScope(PermFriends, PermOffline, PermGroups)
+			So(s.Mask(), ShouldEqual, 2+65536+262144)
+			So(ScopeFromMask(s.Mask()), ShouldResemble, s)
+			So(ScopeFromMask(0), ShouldBeEmpty)
+		})
+		Convey("Every user permission has own bit", func() {
+			all := 0
+			for p, bit := range userMask {
+				So(all&bit, ShouldEqual, 0)
+				all |= bit
+				So(ScopeFromMask(bit), ShouldResemble, NewScope(p))
+			}
+			So(ScopeFromMask(all), ShouldHaveLength, len(userMask))
+		})
+		Convey("Community", func() {
+			s := NewScope(PermManage, PermMessages, PermPhotos)
+			So(s.GroupMask(), ShouldEqual, 262144+4096+4)
+			So(GroupScopeFromMask(s.GroupMask()), ShouldResemble, s)
+			// manage and groups share bit
+			So(GroupScopeFromMask(262144).Has(PermManage), ShouldBeTrue)
+			So(ScopeFromMask(262144).Has(PermGroups), ShouldBeTrue)
+		})
+		Convey("Unknown permissions are ignored", func() {
+			So(NewScope(Permission("unknown"), PermNotify).Mask(), ShouldEqual, 1)
+		})
+	})
+	Convey("Parse", t, func() {
+		So(ParseScope("friends, offline,,groups"), ShouldResemble, NewScope(PermFriends, PermOffline, PermGroups))
+		So(ParseScope("friends,offline").String(), ShouldEqual, "friends,offline")
+		So(ParseScope("65538"), ShouldResemble, NewScope(PermFriends, PermOffline))
+		So(ParseScope(""), ShouldBeEmpty)
+	})
+}
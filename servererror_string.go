@@ -1,60 +1,267 @@
-// generated by stringer -type=ServerError; DO NOT EDIT
+// Code generated by "stringer -type=ServerError"; DO NOT EDIT.
 
 package vk
 
-import "fmt"
+import "strconv"
 
-const (
-	_ServerError_name_0 = "ErrBadResponseCodeErrZeroErrUnknownErrApplicationDisabledErrUnknownMethodErrInvalidSignatureErrAuthFailedErrTooManyRequestsErrInsufficientPermissionsErrInvalidRequestErrTooManyOneTypeRequestsErrInternalServerErrorErrAppInTestModeErrCaptchaNeededErrNotAllowedErrHttpsOnlyErrNeedValidationErrStandaloneOnlyErrStandaloneOpenAPIOnlyErrMethodDisabledErrNeedConfirmation"
-	_ServerError_name_1 = "ErrOneOfParametersInvalidErrInvalidAPIID"
-	_ServerError_name_2 = "ErrInvalidAUserID"
-	_ServerError_name_3 = "ErrInvalidTimestamp"
-	_ServerError_name_4 = "ErrAlbumAccessProhibited"
-	_ServerError_name_5 = "ErrGroupAccessProhibited"
-	_ServerError_name_6 = "ErrAlbumOverflow"
-	_ServerError_name_7 = "ErrMoneyTransferNotAllowed"
-	_ServerError_name_8 = "ErrInsufficientPermissionsAd"
-	_ServerError_name_9 = "ErrInternalServerErrorAd"
-)
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[ErrZero-0]
+	_ = x[ErrUnknown-1]
+	_ = x[ErrApplicationDisabled-2]
+	_ = x[ErrUnknownMethod-3]
+	_ = x[ErrInvalidSignature-4]
+	_ = x[ErrAuthFailed-5]
+	_ = x[ErrTooManyRequests-6]
+	_ = x[ErrInsufficientPermissions-7]
+	_ = x[ErrInvalidRequest-8]
+	_ = x[ErrTooManyOneTypeRequests-9]
+	_ = x[ErrInternalServerError-10]
+	_ = x[ErrAppInTestMode-11]
+	_ = x[ErrCaptchaNeeded-14]
+	_ = x[ErrNotAllowed-15]
+	_ = x[ErrHttpsOnly-16]
+	_ = x[ErrNeedValidation-17]
+	_ = x[ErrUserDeleted-18]
+	_ = x[ErrStandaloneOnly-20]
+	_ = x[ErrStandaloneOpenAPIOnly-21]
+	_ = x[ErrMethodDisabled-23]
+	_ = x[ErrNeedConfirmation-24]
+	_ = x[ErrGroupAuthFailed-27]
+	_ = x[ErrAppAuthFailed-28]
+	_ = x[ErrRateLimitReached-29]
+	_ = x[ErrPrivateProfile-30]
+	_ = x[ErrNotImplemented-33]
+	_ = x[ErrUserBanned-37]
+	_ = x[ErrUnknownApplication-38]
+	_ = x[ErrUnknownUser-39]
+	_ = x[ErrUnknownGroup-40]
+	_ = x[ErrAdditionalSignupRequired-41]
+	_ = x[ErrOneOfParametersInvalid-100]
+	_ = x[ErrInvalidAPIID-101]
+	_ = x[ErrOutOfLimits-103]
+	_ = x[ErrNotFound-104]
+	_ = x[ErrCouldNotSaveFile-105]
+	_ = x[ErrUnableToProcessAction-106]
+	_ = x[ErrInvalidAUserID-113]
+	_ = x[ErrInvalidAlbumID-114]
+	_ = x[ErrInvalidServer-118]
+	_ = x[ErrInvalidHash-121]
+	_ = x[ErrInvalidGroupID-125]
+	_ = x[ErrInvalidPhoto-129]
+	_ = x[ErrPageNotFound-140]
+	_ = x[ErrPageAccessDenied-141]
+	_ = x[ErrMobileNumberUnknown-146]
+	_ = x[ErrInsufficientFunds-147]
+	_ = x[ErrMenuAccessDenied-148]
+	_ = x[ErrInvalidTimestamp-150]
+	_ = x[ErrInvalidListID-171]
+	_ = x[ErrTooManyLists-173]
+	_ = x[ErrCannotAddSelfAsFriend-174]
+	_ = x[ErrBlacklistedByUser-175]
+	_ = x[ErrUserBlacklisted-176]
+	_ = x[ErrFriendNotFound-177]
+	_ = x[ErrNoteNotFound-180]
+	_ = x[ErrNoteAccessDenied-181]
+	_ = x[ErrNoteCommentsClosed-182]
+	_ = x[ErrCommentAccessDenied-183]
+	_ = x[ErrAlbumAccessProhibited-200]
+	_ = x[ErrAudioAccessProhibited-201]
+	_ = x[ErrGroupAccessProhibited-203]
+	_ = x[ErrObjectAccessDenied-204]
+	_ = x[ErrWallPostAccessDenied-210]
+	_ = x[ErrWallCommentAccessDenied-211]
+	_ = x[ErrPostCommentsAccessDenied-212]
+	_ = x[ErrStatusRepliesAccessDenied-213]
+	_ = x[ErrPostAddAccessDenied-214]
+	_ = x[ErrAdPostRecentlyAdded-219]
+	_ = x[ErrTooManyRecipients-220]
+	_ = x[ErrHyperlinksForbidden-222]
+	_ = x[ErrTooManyReplies-223]
+	_ = x[ErrTooManyAdPosts-224]
+	_ = x[ErrDonutDisabled-225]
+	_ = x[ErrPollAccessDenied-250]
+	_ = x[ErrInvalidPollID-251]
+	_ = x[ErrInvalidPollAnswerID-252]
+	_ = x[ErrPollVoteRequired-253]
+	_ = x[ErrGroupsListAccessDenied-260]
+	_ = x[ErrAlbumOverflow-300]
+	_ = x[ErrMoneyTransferNotAllowed-500]
+	_ = x[ErrNotEnoughVotes-503]
+	_ = x[ErrInsufficientPermissionsAd-600]
+	_ = x[ErrTooManyAdsActions-601]
+	_ = x[ErrInternalServerErrorAd-603]
+	_ = x[ErrCannotEditCreatorRole-700]
+	_ = x[ErrUserNotInClub-701]
+	_ = x[ErrTooManyOfficers-702]
+	_ = x[ErrNeed2FA-703]
+	_ = x[ErrUserNeed2FA-704]
+	_ = x[ErrAppNotInstalledInCommunity-711]
+	_ = x[ErrVideoAlreadyAdded-800]
+	_ = x[ErrVideoCommentsClosed-801]
+	_ = x[ErrMessagesBlacklisted-900]
+	_ = x[ErrMessagesForbidden-901]
+	_ = x[ErrMessagesPrivacy-902]
+	_ = x[ErrMessageTooOld-909]
+	_ = x[ErrMessageTooBig-910]
+	_ = x[ErrInvalidKeyboard-911]
+	_ = x[ErrChatBotFeature-912]
+	_ = x[ErrTooManyForwarded-913]
+	_ = x[ErrMessageTooLong-914]
+	_ = x[ErrChatAccessDenied-917]
+	_ = x[ErrCannotForward-921]
+	_ = x[ErrNotChatAdmin-925]
+	_ = x[ErrPeerInteractionForbidden-932]
+	_ = x[ErrContactNotFound-936]
+	_ = x[ErrMessageRequestAlreadySent-939]
+	_ = x[ErrTooManyPosts-940]
+	_ = x[ErrTooManyPinned-942]
+	_ = x[ErrChatDisabled-945]
+	_ = x[ErrChatNotSupported-946]
+	_ = x[ErrInvalidDocumentID-1150]
+	_ = x[ErrDocumentDeleteDenied-1151]
+	_ = x[ErrInvalidDocumentTitle-1152]
+	_ = x[ErrDocumentAccessDenied-1153]
+	_ = x[ErrOriginalPhotoChanged-1160]
+	_ = x[ErrTooManyFeedLists-1170]
+	_ = x[ErrInvalidScreenName-1260]
+	_ = x[ErrCatalogUnavailable-1310]
+	_ = x[ErrCatalogCategoriesUnavailable-1311]
+	_ = x[ErrBadResponseCode - -1]
+}
+
+const _ServerError_name = "ErrBadResponseCodeErrZeroErrUnknownErrApplicationDisabledErrUnknownMethodErrInvalidSignatureErrAuthFailedErrTooManyRequestsErrInsufficientPermissionsErrInvalidRequestErrTooManyOneTypeRequestsErrInternalServerErrorErrAppInTestModeErrCaptchaNeededErrNotAllowedErrHttpsOnlyErrNeedValidationErrUserDeletedErrStandaloneOnlyErrStandaloneOpenAPIOnlyErrMethodDisabledErrNeedConfirmationErrGroupAuthFailedErrAppAuthFailedErrRateLimitReachedErrPrivateProfileErrNotImplementedErrUserBannedErrUnknownApplicationErrUnknownUserErrUnknownGroupErrAdditionalSignupRequiredErrOneOfParametersInvalidErrInvalidAPIIDErrOutOfLimitsErrNotFoundErrCouldNotSaveFileErrUnableToProcessActionErrInvalidAUserIDErrInvalidAlbumIDErrInvalidServerErrInvalidHashErrInvalidGroupIDErrInvalidPhotoErrPageNotFoundErrPageAccessDeniedErrMobileNumberUnknownErrInsufficientFundsErrMenuAccessDeniedErrInvalidTimestampErrInvalidListIDErrTooManyListsErrCannotAddSelfAsFriendErrBlacklistedByUserErrUserBlacklistedErrFriendNotFoundErrNoteNotFoundErrNoteAccessDeniedErrNoteCommentsClosedErrCommentAccessDeniedErrAlbumAccessProhibitedErrAudioAccessProhibitedErrGroupAccessProhibitedErrObjectAccessDeniedErrWallPostAccessDeniedErrWallCommentAccessDeniedErrPostCommentsAccessDeniedErrStatusRepliesAccessDeniedErrPostAddAccessDeniedErrAdPostRecentlyAddedErrTooManyRecipientsErrHyperlinksForbiddenErrTooManyRepliesErrTooManyAdPostsErrDonutDisabledErrPollAccessDeniedErrInvalidPollIDErrInvalidPollAnswerIDErrPollVoteRequiredErrGroupsListAccessDeniedErrAlbumOverflowErrMoneyTransferNotAllowedErrNotEnoughVotesErrInsufficientPermissionsAdErrTooManyAdsActionsErrInternalServerErrorAdErrCannotEditCreatorRoleErrUserNotInClubErrTooManyOfficersErrNeed2FAErrUserNeed2FAErrAppNotInstalledInCommunityErrVideoAlreadyAddedErrVideoCommentsClosedErrMessagesBlacklistedErrMessagesForbiddenErrMessagesPrivacyErrMessageTooOldErrMessageTooBigErrInvalidKeyboardErrChatBotFeatureErrTooManyForwardedErrMessageTooLongErrChatAccessDeniedErrCannotForwardErrNotChatAdminErrPeerInteractionForbiddenErrContactNotFoundErrMessageRequestAlreadySentErrTooManyPostsErrTooManyPinnedErrChatDisabledErrChatNotSupportedErrInvalidDocumentIDErrDocumentDeleteDeniedErrInvalidDocumentTitleErrDocumentAccessDeniedErrOriginalPhotoChangedErrTooManyFeedListsErrInvalidScreenNameErrCatalogUnavailableErrCatalogCategoriesUnavailable"
 
-var (
-	_ServerError_index_0 = [...]uint16{0, 18, 25, 35, 57, 73, 92, 105, 123, 149, 166, 191, 213, 229, 245, 258, 270, 287, 304, 328, 345, 364}
-	_ServerError_index_1 = [...]uint8{0, 25, 40}
-	_ServerError_index_2 = [...]uint8{0, 17}
-	_ServerError_index_3 = [...]uint8{0, 19}
-	_ServerError_index_4 = [...]uint8{0, 24}
-	_ServerError_index_5 = [...]uint8{0, 24}
-	_ServerError_index_6 = [...]uint8{0, 16}
-	_ServerError_index_7 = [...]uint8{0, 26}
-	_ServerError_index_8 = [...]uint8{0, 28}
-	_ServerError_index_9 = [...]uint8{0, 24}
-)
+var _ServerError_map = map[ServerError]string{
+	-1:   _ServerError_name[0:18],
+	0:    _ServerError_name[18:25],
+	1:    _ServerError_name[25:35],
+	2:    _ServerError_name[35:57],
+	3:    _ServerError_name[57:73],
+	4:    _ServerError_name[73:92],
+	5:    _ServerError_name[92:105],
+	6:    _ServerError_name[105:123],
+	7:    _ServerError_name[123:149],
+	8:    _ServerError_name[149:166],
+	9:    _ServerError_name[166:191],
+	10:   _ServerError_name[191:213],
+	11:   _ServerError_name[213:229],
+	14:   _ServerError_name[229:245],
+	15:   _ServerError_name[245:258],
+	16:   _ServerError_name[258:270],
+	17:   _ServerError_name[270:287],
+	18:   _ServerError_name[287:301],
+	20:   _ServerError_name[301:318],
+	21:   _ServerError_name[318:342],
+	23:   _ServerError_name[342:359],
+	24:   _ServerError_name[359:378],
+	27:   _ServerError_name[378:396],
+	28:   _ServerError_name[396:412],
+	29:   _ServerError_name[412:431],
+	30:   _ServerError_name[431:448],
+	33:   _ServerError_name[448:465],
+	37:   _ServerError_name[465:478],
+	38:   _ServerError_name[478:499],
+	39:   _ServerError_name[499:513],
+	40:   _ServerError_name[513:528],
+	41:   _ServerError_name[528:555],
+	100:  _ServerError_name[555:580],
+	101:  _ServerError_name[580:595],
+	103:  _ServerError_name[595:609],
+	104:  _ServerError_name[609:620],
+	105:  _ServerError_name[620:639],
+	106:  _ServerError_name[639:663],
+	113:  _ServerError_name[663:680],
+	114:  _ServerError_name[680:697],
+	118:  _ServerError_name[697:713],
+	121:  _ServerError_name[713:727],
+	125:  _ServerError_name[727:744],
+	129:  _ServerError_name[744:759],
+	140:  _ServerError_name[759:774],
+	141:  _ServerError_name[774:793],
+	146:  _ServerError_name[793:815],
+	147:  _ServerError_name[815:835],
+	148:  _ServerError_name[835:854],
+	150:  _ServerError_name[854:873],
+	171:  _ServerError_name[873:889],
+	173:  _ServerError_name[889:904],
+	174:  _ServerError_name[904:928],
+	175:  _ServerError_name[928:948],
+	176:  _ServerError_name[948:966],
+	177:  _ServerError_name[966:983],
+	180:  _ServerError_name[983:998],
+	181:  _ServerError_name[998:1017],
+	182:  _ServerError_name[1017:1038],
+	183:  _ServerError_name[1038:1060],
+	200:  _ServerError_name[1060:1084],
+	201:  _ServerError_name[1084:1108],
+	203:  _ServerError_name[1108:1132],
+	204:  _ServerError_name[1132:1153],
+	210:  _ServerError_name[1153:1176],
+	211:  _ServerError_name[1176:1202],
+	212:  _ServerError_name[1202:1229],
+	213:  _ServerError_name[1229:1257],
+	214:  _ServerError_name[1257:1279],
+	219:  _ServerError_name[1279:1301],
+	220:  _ServerError_name[1301:1321],
+	222:  _ServerError_name[1321:1343],
+	223:  _ServerError_name[1343:1360],
+	224:  _ServerError_name[1360:1377],
+	225:  _ServerError_name[1377:1393],
+	250:  _ServerError_name[1393:1412],
+	251:  _ServerError_name[1412:1428],
+	252:  _ServerError_name[1428:1450],
+	253:  _ServerError_name[1450:1469],
+	260:  _ServerError_name[1469:1494],
+	300:  _ServerError_name[1494:1510],
+	500:  _ServerError_name[1510:1536],
+	503:  _ServerError_name[1536:1553],
+	600:  _ServerError_name[1553:1581],
+	601:  _ServerError_name[1581:1601],
+	603:  _ServerError_name[1601:1625],
+	700:  _ServerError_name[1625:1649],
+	701:  _ServerError_name[1649:1665],
+	702:  _ServerError_name[1665:1683],
+	703:  _ServerError_name[1683:1693],
+	704:  _ServerError_name[1693:1707],
+	711:  _ServerError_name[1707:1736],
+	800:  _ServerError_name[1736:1756],
+	801:  _ServerError_name[1756:1778],
+	900:  _ServerError_name[1778:1800],
+	901:  _ServerError_name[1800:1820],
+	902:  _ServerError_name[1820:1838],
+	909:  _ServerError_name[1838:1854],
+	910:  _ServerError_name[1854:1870],
+	911:  _ServerError_name[1870:1888],
+	912:  _ServerError_name[1888:1905],
+	913:  _ServerError_name[1905:1924],
+	914:  _ServerError_name[1924:1941],
+	917:  _ServerError_name[1941:1960],
+	921:  _ServerError_name[1960:1976],
+	925:  _ServerError_name[1976:1991],
+	932:  _ServerError_name[1991:2018],
+	936:  _ServerError_name[2018:2036],
+	939:  _ServerError_name[2036:2064],
+	940:  _ServerError_name[2064:2079],
+	942:  _ServerError_name[2079:2095],
+	945:  _ServerError_name[2095:2110],
+	946:  _ServerError_name[2110:2129],
+	1150: _ServerError_name[2129:2149],
+	1151: _ServerError_name[2149:2172],
+	1152: _ServerError_name[2172:2195],
+	1153: _ServerError_name[2195:2218],
+	1160: _ServerError_name[2218:2241],
+	1170: _ServerError_name[2241:2260],
+	1260: _ServerError_name[2260:2280],
+	1310: _ServerError_name[2280:2301],
+	1311: _ServerError_name[2301:2332],
+}
 
 func (i ServerError) String() string {
-	switch {
-	case -1 <= i && i <= 19:
-		i -= -1
-		return _ServerError_name_0[_ServerError_index_0[i]:_ServerError_index_0[i+1]]
-	case 100 <= i && i <= 101:
-		i -= 100
-		return _ServerError_name_1[_ServerError_index_1[i]:_ServerError_index_1[i+1]]
-	case i == 113:
-		return _ServerError_name_2
-	case i == 150:
-		return _ServerError_name_3
-	case i == 200:
-		return _ServerError_name_4
-	case i == 203:
-		return _ServerError_name_5
-	case i == 300:
-		return _ServerError_name_6
-	case i == 500:
-		return _ServerError_name_7
-	case i == 600:
-		return _ServerError_name_8
-	case i == 603:
-		return _ServerError_name_9
-	default:
-		return fmt.Sprintf("ServerError(%d)", i)
+	if str, ok := _ServerError_map[i]; ok {
+		return str
 	}
+	return "ServerError(" + strconv.FormatInt(int64(i), 10) + ")"
 }
@@ -0,0 +1,414 @@
+package vk
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// storeVersion is version of file store format
+	storeVersion = 2
+	storeMode    = 0600
+
+	// argon2id parameters of key derivation, as recommended
+	// by RFC 9106 for memory constrained environments
+	storeKeyTime    = 3
+	storeKeyMemory  = 64 * 1024
+	storeKeyThreads = 4
+	storeKeySize    = 32
+	storeSaltSize   = 16
+)
+
+var (
+	// ErrTokenNotFound is returned by TokenStore when there
+	// is no token for identity
+	ErrTokenNotFound = errors.New("vk: token not found")
+	// ErrTokenExpired is returned when stored token is expired
+	ErrTokenExpired = errors.New("vk: token expired")
+	// ErrStoreDecrypt is returned by FileStore when file can not
+	// be decrypted, usually because of wrong passphrase
+	ErrStoreDecrypt = errors.New("vk: unable to decrypt token store")
+	// ErrEmptyPassphrase is returned by NewFileStore for empty passphrase
+	ErrEmptyPassphrase = errors.New("vk: token store passphrase is empty")
+)
+
+// IdentityKind is kind of token owner
+type IdentityKind string
+
+// Possible token owners
+const (
+	IdentityUser  IdentityKind = "user"
+	IdentityGroup IdentityKind = "group"
+)
+
+// Identity is owner of access token, that is user or community
+type Identity struct {
+	Kind IdentityKind `json:"kind"`
+	ID   int          `json:"id"`
+}
+
+// UserIdentity returns identity of user
+func UserIdentity(id int) Identity {
+	return Identity{IdentityUser, id}
+}
+
+// GroupIdentity returns identity of community
+func GroupIdentity(id int) Identity {
+	return Identity{IdentityGroup, id}
+}
+
+func (i Identity) String() string {
+	return fmt.Sprintf("%s:%d", i.Kind, i.ID)
+}
+
+// StoredToken is access token of identity saved in TokenStore
+type StoredToken struct {
+	Identity    Identity `json:"identity"`
+	AccessToken string   `json:"access_token"`
+	// Expiry is time when token expires, zero for unlimited
+	Expiry time.Time `json:"expiry"`
+	// Scope granted to token, if known
+	Scope   Scope     `json:"scope,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// Expired returns true if token has limited lifetime that is over
+func (t StoredToken) Expired() bool {
+	return !t.Expiry.IsZero() && !time.Now().Before(t.Expiry)
+}
+
+// StoredTokens returns user token and community tokens
+// of oauth token, ready to be saved in TokenStore
+func StoredTokens(token Token, scope Scope) []StoredToken {
+	var tokens []StoredToken
+	now := time.Now()
+	if len(token.AccessToken) != 0 {
+		tokens = append(tokens, StoredToken{
+			Identity:    UserIdentity(token.UserID),
+			AccessToken: token.AccessToken,
+			Expiry:      token.Expiry,
+			Scope:       scope,
+			Created:     now,
+		})
+	}
+	for _, g := range token.Groups {
+		tokens = append(tokens, StoredToken{
+			Identity:    GroupIdentity(g.GroupID),
+			AccessToken: g.AccessToken,
+			Expiry:      token.Expiry,
+			Scope:       scope,
+			Created:     now,
+		})
+	}
+	return tokens
+}
+
+// TokenStore persists access tokens by identity
+type TokenStore interface {
+	// Get returns token of identity or ErrTokenNotFound
+	Get(id Identity) (StoredToken, error)
+	// Put saves token, replacing token of same identity
+	Put(token StoredToken) error
+	// Delete removes token of identity, it is not
+	// an error if there is no such token
+	Delete(id Identity) error
+	// List returns all tokens ordered by identity
+	List() ([]StoredToken, error)
+}
+
+// NewWithStore creates vk api client that performs all resource
+// requests with stored token of identity. Token of community
+// is limited with rate of community tokens.
+func NewWithStore(store TokenStore, id Identity, options ...Option) (*Client, error) {
+	token, err := store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if token.Expired() {
+		return nil, ErrTokenExpired
+	}
+	c := NewWithToken(token.AccessToken, options...)
+	if id.Kind == IdentityGroup {
+		c.SetTokenKind(token.AccessToken, TokenGroup)
+	}
+	return c, nil
+}
+
+// tokens is set of tokens by identity
+type tokens map[Identity]StoredToken
+
+func (t tokens) list() []StoredToken {
+	list := make([]StoredToken, 0, len(t))
+	for _, v := range t {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].Identity, list[j].Identity
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		return a.ID < b.ID
+	})
+	return list
+}
+
+// MemoryStore is TokenStore that keeps tokens in memory.
+// It is safe for concurrent use.
+type MemoryStore struct {
+	mux    sync.Mutex
+	tokens tokens
+}
+
+// NewMemoryStore returns empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(tokens)}
+}
+
+// Get implements TokenStore
+func (s *MemoryStore) Get(id Identity) (StoredToken, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	token, ok := s.tokens[id]
+	if !ok {
+		return token, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// Put implements TokenStore
+func (s *MemoryStore) Put(token StoredToken) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.tokens[token.Identity] = token
+	return nil
+}
+
+// Delete implements TokenStore
+func (s *MemoryStore) Delete(id Identity) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.tokens, id)
+	return nil
+}
+
+// List implements TokenStore
+func (s *MemoryStore) List() ([]StoredToken, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.tokens.list(), nil
+}
+
+// FileStore is TokenStore that keeps tokens in JSON file
+// encrypted with AES-GCM. Key is derived from passphrase with
+// argon2id and random salt, that is stored in file. File is
+// rewritten atomically on every change. It is safe for concurrent
+// use within single process.
+type FileStore struct {
+	path       string
+	passphrase []byte
+
+	mux sync.Mutex
+	// salt of last read or written file and key derived with it,
+	// derivation is slow by design and is not repeated
+	salt []byte
+	key  []byte
+}
+
+// storeFile is encrypted content of FileStore
+type storeFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// NewFileStore returns FileStore that keeps tokens in file
+// at path, file is created on first Put
+func NewFileStore(path, passphrase string) (*FileStore, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	return &FileStore{
+		path:       path,
+		passphrase: []byte(passphrase),
+	}, nil
+}
+
+// aead returns cipher with key derived from passphrase and salt
+func (s *FileStore) aead(salt []byte) (cipher.AEAD, error) {
+	if s.key == nil || !bytes.Equal(salt, s.salt) {
+		s.key = argon2.IDKey(s.passphrase, salt, storeKeyTime, storeKeyMemory, storeKeyThreads, storeKeySize)
+		s.salt = salt
+	}
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// load reads and decrypts tokens, missing file is empty store
+func (s *FileStore) load() (tokens, error) {
+	t := make(tokens)
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		// new file gets new salt
+		s.salt, s.key = nil, nil
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f storeFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Version != storeVersion {
+		return nil, fmt.Errorf("vk: unsupported token store version %d", f.Version)
+	}
+	if len(f.Salt) != storeSaltSize {
+		return nil, ErrStoreDecrypt
+	}
+	aead, err := s.aead(f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, ErrStoreDecrypt
+	}
+	plain, err := aead.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrStoreDecrypt
+	}
+	var list []StoredToken
+	if err = json.Unmarshal(plain, &list); err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		t[v.Identity] = v
+	}
+	return t, nil
+}
+
+// save encrypts tokens and atomically replaces file
+func (s *FileStore) save(t tokens) error {
+	plain, err := json.Marshal(t.list())
+	if err != nil {
+		return err
+	}
+	salt := s.salt
+	if salt == nil {
+		salt = make([]byte, storeSaltSize)
+		if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+			return err
+		}
+	}
+	aead, err := s.aead(salt)
+	if err != nil {
+		return err
+	}
+	f := storeFile{Version: storeVersion, Salt: salt, Nonce: make([]byte, aead.NonceSize())}
+	if _, err = io.ReadFull(rand.Reader, f.Nonce); err != nil {
+		return err
+	}
+	f.Data = aead.Seal(nil, f.Nonce, plain, nil)
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, storeMode)
+}
+
+// writeFileAtomic writes data to temporary file and renames it to path,
+// so readers never observe partially written file
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	// data must reach disk before rename, otherwise crash
+	// can leave empty file in place of old one
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// update loads tokens, applies fn and saves result
+func (s *FileStore) update(fn func(t tokens)) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	t, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(t)
+	return s.save(t)
+}
+
+// Get implements TokenStore
+func (s *FileStore) Get(id Identity) (StoredToken, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	t, err := s.load()
+	if err != nil {
+		return StoredToken{}, err
+	}
+	token, ok := t[id]
+	if !ok {
+		return token, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// Put implements TokenStore
+func (s *FileStore) Put(token StoredToken) error {
+	return s.update(func(t tokens) {
+		t[token.Identity] = token
+	})
+}
+
+// Delete implements TokenStore
+func (s *FileStore) Delete(id Identity) error {
+	return s.update(func(t tokens) {
+		delete(t, id)
+	})
+}
+
+// List implements TokenStore
+func (s *FileStore) List() ([]StoredToken, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	t, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return t.list(), nil
+}
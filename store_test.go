@@ -0,0 +1,162 @@
+package vk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testTokenStore(store TokenStore) {
+	user := StoredToken{
+		Identity:    UserIdentity(1),
+		AccessToken: "user-secret-token",
+		Scope:       NewScope(PermGroups, PermOffline),
+	}
+	group := StoredToken{Identity: GroupIdentity(1), AccessToken: "group-secret-token"}
+
+	_, err := store.Get(user.Identity)
+	So(err, ShouldEqual, ErrTokenNotFound)
+	So(store.Put(group), ShouldBeNil)
+	So(store.Put(user), ShouldBeNil)
+
+	token, err := store.Get(user.Identity)
+	So(err, ShouldBeNil)
+	So(token.AccessToken, ShouldEqual, user.AccessToken)
+	So(token.Scope, ShouldResemble, user.Scope)
+	token, err = store.Get(group.Identity)
+	So(err, ShouldBeNil)
+	So(token.AccessToken, ShouldEqual, group.AccessToken)
+
+	list, err := store.List()
+	So(err, ShouldBeNil)
+	So(list, ShouldHaveLength, 2)
+	So(list[0].Identity, ShouldResemble, user.Identity)
+	So(list[1].Identity, ShouldResemble, group.Identity)
+
+	user.AccessToken = "updated"
+	So(store.Put(user), ShouldBeNil)
+	token, err = store.Get(user.Identity)
+	So(err, ShouldBeNil)
+	So(token.AccessToken, ShouldEqual, "updated")
+
+	So(store.Delete(user.Identity), ShouldBeNil)
+	So(store.Delete(user.Identity), ShouldBeNil)
+	_, err = store.Get(user.Identity)
+	So(err, ShouldEqual, ErrTokenNotFound)
+	list, err = store.List()
+	So(err, ShouldBeNil)
+	So(list, ShouldHaveLength, 1)
+}
+
+func TestTokenStore(t *testing.T) {
+	Convey("Memory store", t, func() {
+		testTokenStore(NewMemoryStore())
+	})
+	Convey("File store", t, func() {
+		dir, err := ioutil.TempDir("", "vk-store")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "tokens.json")
+		open := func(path, passphrase string) *FileStore {
+			store, err := NewFileStore(path, passphrase)
+			So(err, ShouldBeNil)
+			return store
+		}
+		store := open(path, "passphrase")
+		testTokenStore(store)
+
+		Convey("Encrypted", func() {
+			data, err := ioutil.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldNotContainSubstring, "group-secret-token")
+			info, err := os.Stat(path)
+			So(err, ShouldBeNil)
+			So(info.Mode().Perm(), ShouldEqual, os.FileMode(storeMode))
+		})
+		Convey("Persisted", func() {
+			token, err := open(path, "passphrase").Get(GroupIdentity(1))
+			So(err, ShouldBeNil)
+			So(token.AccessToken, ShouldEqual, "group-secret-token")
+		})
+		Convey("Wrong passphrase", func() {
+			_, err := open(path, "wrong").List()
+			So(err, ShouldEqual, ErrStoreDecrypt)
+			So(open(path, "wrong").Put(StoredToken{}), ShouldEqual, ErrStoreDecrypt)
+		})
+		Convey("Key is derived with random salt", func() {
+			read := func(path string) storeFile {
+				data, err := ioutil.ReadFile(path)
+				So(err, ShouldBeNil)
+				var f storeFile
+				So(json.Unmarshal(data, &f), ShouldBeNil)
+				return f
+			}
+			f := read(path)
+			So(f.Version, ShouldEqual, storeVersion)
+			So(f.Salt, ShouldHaveLength, storeSaltSize)
+			So(store.Put(StoredToken{Identity: UserIdentity(10), AccessToken: "other"}), ShouldBeNil)
+			So(read(path).Salt, ShouldResemble, f.Salt)
+
+			other := filepath.Join(dir, "other.json")
+			So(open(other, "passphrase").Put(StoredToken{Identity: UserIdentity(1)}), ShouldBeNil)
+			So(read(other).Salt, ShouldNotResemble, f.Salt)
+		})
+		Convey("Empty passphrase", func() {
+			_, err := NewFileStore(path, "")
+			So(err, ShouldEqual, ErrEmptyPassphrase)
+		})
+		Convey("No temporary files left", func() {
+			files, err := ioutil.ReadDir(dir)
+			So(err, ShouldBeNil)
+			So(files, ShouldHaveLength, 1)
+		})
+	})
+	Convey("Stored tokens", t, func() {
+		expiry := time.Now().Add(time.Hour)
+		tokens := StoredTokens(Token{
+			AccessToken: "user",
+			UserID:      10,
+			Expiry:      expiry,
+			Groups:      []GroupToken{{GroupID: 20, AccessToken: "group"}},
+		}, NewScope(PermGroups))
+		So(tokens, ShouldHaveLength, 2)
+		So(tokens[0].Identity, ShouldResemble, UserIdentity(10))
+		So(tokens[0].Expiry, ShouldEqual, expiry)
+		So(tokens[0].Scope.Has(PermGroups), ShouldBeTrue)
+		So(tokens[1].Identity, ShouldResemble, GroupIdentity(20))
+		So(tokens[1].AccessToken, ShouldEqual, "group")
+		So(GroupIdentity(20).String(), ShouldEqual, "group:20")
+	})
+	Convey("Client from store", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get(paramToken) != "stored" || !strings.HasSuffix(r.URL.Path, methodGroupsGet) {
+				w.Write([]byte(`{"error": {"error_code": 5, "error_msg": "User authorization failed"}}`))
+				return
+			}
+			w.Write([]byte(`{"response": {"count": 0, "items": []}}`))
+		}))
+		defer server.Close()
+
+		store := NewMemoryStore()
+		store.Put(StoredToken{Identity: UserIdentity(1), AccessToken: "stored"})
+		store.Put(StoredToken{Identity: UserIdentity(2), AccessToken: "old", Expiry: time.Now().Add(-time.Second)})
+
+		client, err := NewWithStore(store, UserIdentity(1), WithBaseURL(server.URL), WithLimiter(nil))
+		So(err, ShouldBeNil)
+		_, err = client.Groups.Get(GroupGetFields{})
+		So(err, ShouldBeNil)
+
+		_, err = NewWithStore(store, UserIdentity(2))
+		So(err, ShouldEqual, ErrTokenExpired)
+		_, err = NewWithStore(store, UserIdentity(3))
+		So(err, ShouldEqual, ErrTokenNotFound)
+	})
+}
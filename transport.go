@@ -2,11 +2,11 @@ package vk
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
@@ -63,8 +63,7 @@ func (v *Bool) UnmarshalJSON(data []byte) error {
 	} else if data[0] == byteZero {
 		*v = false
 	} else {
-		log.Println("unmarshal:", bytes.NewBuffer(data))
-		return errors.New("json unmarshal: Bool value overflow")
+		return fmt.Errorf("json unmarshal: Bool value overflow: %s", data)
 	}
 	return nil
 }
@@ -81,57 +80,180 @@ func must(err error) {
 	}
 }
 
+// Do performs request with background context
 func (c *Client) Do(request Request) (response *Response, err error) {
-	response = new(Response)
-	response.setRequest(request)
-	req := request.HTTP()
-	start := time.Now()
-	log.Println("DO", request.Method)
-	var res *http.Response
-	for attempt:= 1; attempt<5; attempt++  {
-		res, err = c.httpClient.Do(req)
+	return c.DoContext(context.Background(), request)
+}
+
+// DoContext performs request, aborting rate limiter waits,
+// retries and http request when ctx is done
+func (c *Client) DoContext(ctx context.Context, request Request) (response *Response, err error) {
+	if c.pool != nil {
+		defer func() {
+			if ctx.Err() == nil {
+				c.pool.Report(request.Token, err)
+			}
+		}()
+	}
+	attempt, captchas := 1, 0
+	for {
+		if c.limiter != nil {
+			if err = c.limiter.Wait(ctx, request.Token); err != nil {
+				return nil, err
+			}
+		}
+		var a Attempt
+		start := time.Now()
+		response, a, err = c.attempt(ctx, request)
+		a.Number = attempt
+		c.logAttempt(request, a, time.Since(start), err)
 		if err == nil {
-			break
+			return response, nil
 		}
-		log.Println("HTTP attempt", err, attempt)
-		time.Sleep(time.Second * 3)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if e, ok := err.(Error); ok && e.Code == ErrCaptchaNeeded &&
+			c.captcha != nil && captchas < maxCaptchaAttempts {
+			// re-sending request with solved captcha
+			// is not counted as attempt
+			captchas++
+			key, captchaErr := c.captcha.SolveCaptcha(ctx, e.CaptchaImg)
+			if captchaErr != nil {
+				return response, captchaErr
+			}
+			request = request.withCaptcha(e.CaptchaSID, key)
+			continue
+		}
+		delay, retry := c.retry.Retry(a)
+		if retry && c.pool != nil {
+			// failed token is benched and other one
+			// can be used without waiting
+			if token, ok := c.pool.replace(request.Token, err); ok {
+				request.Token = token
+				delay = 0
+			}
+		}
+		if !retry {
+			if a.Err != nil || a.Code == ErrBadResponseCode {
+				err = newRequestError(request, a, err)
+			}
+			return response, err
+		}
+		c.logger.Log("retry", Fields{
+			LogMethod:  request.Method,
+			LogAttempt: attempt,
+			LogDelay:   delay,
+		})
+		if err = sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		attempt++
 	}
+}
+
+// attempt performs single http request and processes response
+func (c *Client) attempt(ctx context.Context, request Request) (response *Response, a Attempt, err error) {
+	req, err := c.endpoint.request(ctx, request)
 	if err != nil {
-		log.Println("HTTP fatal", err)
-		return nil, err
+		return nil, a, err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		a.Err = err
+		return nil, a, err
 	}
-	log.Println("HTTP", res.Status, time.Now().Sub(start))
+	a.StatusCode = res.StatusCode
 	if res.StatusCode != http.StatusOK {
-		return nil, ErrBadResponseCode
+		res.Body.Close()
+		a.Code = ErrBadResponseCode
+		return nil, a, ErrBadResponseCode
 	}
-	return Process(res.Body)
+	response = new(Response)
+	response.setRequest(request)
+	err = vkResponseProcessor{res.Body}.To(response)
+	switch e := err.(type) {
+	case nil:
+	case Error:
+		a.Code = e.Code
+	case Errors:
+		// execute errors are results of nested calls
+	default:
+		a.Err = err
+	}
+	return response, a, err
 }
 
 // HTTP converts to *http.Request
 func (r Request) HTTP() (req *http.Request) {
+	return r.HTTPContext(context.Background())
+}
+
+// HTTPContext converts to *http.Request with provided context
+func (r Request) HTTPContext(ctx context.Context) (req *http.Request) {
+	req, err := defaultEndpoint.request(ctx, r)
+	// only possible error may occur in url parsing
+	// and that is completely unexpected
+	must(err)
+
+	return req
+}
+
+// endpoint describes where and how requests are sent
+type endpoint struct {
+	URL      url.URL
+	Version  string
+	Lang     string
+	TestMode bool
+	// PostThreshold is maximum length of encoded parameters
+	// sent in query string, negative value disables POST
+	PostThreshold int
+}
+
+var defaultEndpoint = endpoint{
+	URL: url.URL{
+		Scheme: defaultScheme,
+		Host:   defaultHost,
+		Path:   defaultPath,
+	},
+	Version:       defaultVersion,
+	PostThreshold: defaultPostThreshold,
+}
+
+// request materializes Request as *http.Request to endpoint
+func (e endpoint) request(ctx context.Context, r Request) (*http.Request, error) {
 	values := url.Values{}
 	// copy old params
 	for k, v := range r.Values {
 		values[k] = v
 	}
-	values.Add(paramVersion, defaultVersion)
+	values.Add(paramVersion, e.Version)
 	values.Add(paramHTTPS, defaultHTTPS)
+	if len(e.Lang) != 0 && len(values.Get(paramLang)) == 0 {
+		values.Set(paramLang, e.Lang)
+	}
+	if e.TestMode {
+		values.Set(paramTestMode, defaultTestMode)
+	}
 	if len(r.Token) != 0 {
 		values.Add(paramToken, r.Token)
 	}
 
-	u := url.URL{}
-	u.Host = defaultHost
-	u.Scheme = defaultScheme
-	u.Path = path.Join(defaultPath, r.Method)
-	u.RawQuery = values.Encode()
-
-	req, err := http.NewRequest(defaultMethod, u.String(), nil)
-	// only possible error may occur in url parsing
-	// and that is completely unexpected
-	must(err)
+	u := e.URL
+	u.Path = path.Join(e.URL.Path, r.Method)
+	encoded := values.Encode()
+	if e.PostThreshold < 0 || len(encoded) <= e.PostThreshold {
+		u.RawQuery = encoded
+		return http.NewRequestWithContext(ctx, defaultMethod, u.String(), nil)
+	}
 
-	return req
+	// parameters, including access token, are sent in body
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentTypeForm)
+	return req, nil
 }
 
 func (r Request) JS() string {
@@ -172,7 +294,6 @@ func (r Raw) String() string {
 
 // MarshalJSON returns *m as the JSON encoding of m.
 func (m Raw) MarshalJSON() ([]byte, error) {
-	log.Println("marshalling to", m)
 	return m, nil
 }
 
@@ -184,6 +305,16 @@ func (m *Raw) UnmarshalJSON(data []byte) error {
 
 type Errors []ExecuteError
 
+// Unwrap returns errors of nested calls, so errors.Is and
+// errors.As can inspect them
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, v := range e {
+		errs[i] = v
+	}
+	return errs
+}
+
 func (e Errors) Error() string {
 	var s []string
 	for _, v := range e {
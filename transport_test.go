@@ -2,13 +2,17 @@ package vk
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -93,7 +97,7 @@ func TestNewRequest(t *testing.T) {
 		r := Request{Token: "token", Method: "users.get", Values: values}
 		req := r.HTTP()
 		So(req.URL.Host, ShouldEqual, defaultHost)
-		So(req.URL.String(), ShouldEqual, "https://api.vk.com/method/users.get?access_token=token&foo=bar&https=1&v=5.35")
+		So(req.URL.String(), ShouldEqual, "https://api.vk.com/method/users.get?access_token=token&foo=bar&https=1&v=5.103")
 	})
 }
 
@@ -159,7 +163,7 @@ func TestRequestSerialization(t *testing.T) {
 		r := Request{Token: "token", Method: "users.get", Values: values}
 		req := r.HTTP()
 		So(req.URL.Host, ShouldEqual, defaultHost)
-		So(req.URL.String(), ShouldEqual, "https://api.vk.com/method/users.get?access_token=token&foo=bar&https=1&v=5.35")
+		So(req.URL.String(), ShouldEqual, "https://api.vk.com/method/users.get?access_token=token&foo=bar&https=1&v=5.103")
 
 		Convey("JS", func() {
 			So(r.JS(), ShouldEqual, `API.users.get({"foo":"bar"})`)
@@ -261,7 +265,10 @@ func TestDo(t *testing.T) {
 			//			response := &Data{}
 
 			_, err := client.Do(request)
-			So(err, ShouldEqual, ErrBadResponseCode)
+			So(errors.Is(err, ErrBadResponseCode), ShouldBeTrue)
+			var requestErr *RequestError
+			So(errors.As(err, &requestErr), ShouldBeTrue)
+			So(requestErr.StatusCode, ShouldEqual, http.StatusBadRequest)
 		})
 		Convey("Http error", func() {
 			client := New()
@@ -271,7 +278,8 @@ func TestDo(t *testing.T) {
 			//			response := &Data{}
 
 			_, err := client.Do(request)
-			So(err, ShouldEqual, ErrBadResponseCode)
+			So(errors.Is(err, ErrBadResponseCode), ShouldBeTrue)
+			So(err.Error(), ShouldStartWith, "users.get: ")
 		})
 	})
 }
@@ -307,3 +315,103 @@ func TestDo(t *testing.T) {
 //		So(expectedRaw, ShouldEqual, res.Response.String())
 //	})
 //}
+
+func TestDoContext(t *testing.T) {
+	Convey("Do with context", t, func() {
+		Convey("Request carries context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req := Request{Method: "users.get"}.HTTPContext(ctx)
+			So(req.Context(), ShouldEqual, ctx)
+		})
+		Convey("Retry loop is aborted", func() {
+			client := New()
+			client.SetHTTPClient(simpleHTTPClientMock{err: io.ErrUnexpectedEOF})
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+			defer cancel()
+			start := time.Now()
+			_, err := client.DoContext(ctx, Request{Method: "users.get"})
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+		})
+		Convey("Limiter wait is aborted", func() {
+			client := New()
+			client.SetHTTPClient(simpleHTTPClientMock{err: io.ErrUnexpectedEOF})
+			client.SetLimiter(NewRateLimiter(time.Hour, 1))
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := client.DoContext(ctx, Request{Method: "users.get"})
+			So(err, ShouldEqual, context.Canceled)
+		})
+	})
+}
+
+func TestPostTransport(t *testing.T) {
+	Convey("POST transport", t, func() {
+		type received struct {
+			method      string
+			contentType string
+			query       string
+			body        string
+		}
+		var got received
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			got = received{r.Method, r.Header.Get("Content-Type"), r.URL.RawQuery, string(body)}
+			w.Write([]byte(`{"response": 1}`))
+		}))
+		defer server.Close()
+
+		values := url.Values{}
+		values.Set("code", strings.Repeat("a", 100))
+		request := Request{Method: "execute", Token: "token", Values: values}
+
+		Convey("Always", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil), WithPOST(0))
+			_, err := client.Do(request)
+			So(err, ShouldBeNil)
+			So(got.method, ShouldEqual, http.MethodPost)
+			So(got.contentType, ShouldEqual, "application/x-www-form-urlencoded")
+			So(got.query, ShouldBeBlank)
+			So(got.body, ShouldEqual, "access_token=token&code="+strings.Repeat("a", 100)+"&https=1&v="+defaultVersion)
+		})
+		Convey("Above threshold", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil), WithPOST(100))
+			_, err := client.Do(request)
+			So(err, ShouldBeNil)
+			So(got.method, ShouldEqual, http.MethodPost)
+			body, err := url.ParseQuery(got.body)
+			So(err, ShouldBeNil)
+			So(body.Get("code"), ShouldEqual, values.Get("code"))
+			So(body.Get(paramToken), ShouldEqual, "token")
+
+			Convey("Below threshold", func() {
+				_, err := client.Do(Request{Method: "users.get", Token: "token"})
+				So(err, ShouldBeNil)
+				So(got.method, ShouldEqual, http.MethodGet)
+				So(got.body, ShouldBeBlank)
+				So(got.query, ShouldContainSubstring, "access_token=token")
+			})
+		})
+		Convey("Default", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil))
+			_, err := client.Do(request)
+			So(err, ShouldBeNil)
+			So(got.method, ShouldEqual, http.MethodGet)
+
+			values := url.Values{}
+			values.Set("user_ids", strings.Repeat("1,", defaultPostThreshold))
+			_, err = client.Do(Request{Method: "users.get", Values: values})
+			So(err, ShouldBeNil)
+			So(got.method, ShouldEqual, http.MethodPost)
+		})
+		Convey("Disabled", func() {
+			client := New(WithBaseURL(server.URL), WithLimiter(nil), WithPOST(-1))
+			values := url.Values{}
+			values.Set("user_ids", strings.Repeat("1,", defaultPostThreshold))
+			_, err := client.Do(Request{Method: "users.get", Values: values})
+			So(err, ShouldBeNil)
+			So(got.method, ShouldEqual, http.MethodGet)
+		})
+	})
+}
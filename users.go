@@ -1,5 +1,12 @@
 package vk
 
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
 // Sex of a user
 type Sex int
 
@@ -19,6 +26,12 @@ func (sex Sex) String() string {
 	return "unknown"
 }
 
+// EncodeValues encodes sex as number for query
+func (sex Sex) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, strconv.Itoa(int(sex)))
+	return nil
+}
+
 type CountryID int
 
 const (
@@ -61,6 +74,12 @@ const (
 	RelationInLove       Relation = 7
 )
 
+// EncodeValues encodes relation as number for query
+func (r Relation) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, strconv.Itoa(int(r)))
+	return nil
+}
+
 type User struct {
 	ID        int     `json:"id"`
 	FirstName string  `json:"first_name"`
@@ -82,3 +101,211 @@ type User struct {
 
 // UserFields all fields that are in User struct
 const UserFields = "id,first_name,last_name,sex,country,city,photo_max,last_seen"
+
+const (
+	methodUsersGet              = "users.get"
+	methodUsersSearch           = "users.search"
+	methodUsersGetFollowers     = "users.getFollowers"
+	methodUsersGetSubscriptions = "users.getSubscriptions"
+
+	// maxUsersGetIDs is limit of user ids in single users.get
+	maxUsersGetIDs = 1000
+)
+
+type Users struct {
+	Resource
+}
+
+// IDs returns ids as strings for UsersGetFields
+func IDs(ids ...int) []string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return s
+}
+
+type UsersGetFields struct {
+	// UserIDs are ids or screen names, current user if blank
+	UserIDs  []string `url:"user_ids,comma,omitempty"`
+	Fields   string   `url:"fields,omitempty"`
+	NameCase string   `url:"name_case,omitempty"`
+}
+
+// UsersResult is page of users
+type UsersResult struct {
+	Count int    `json:"count"`
+	Items []User `json:"items"`
+}
+
+// UnmarshalJSON decodes items that are objects or ids,
+// because ids are returned when no fields are requested
+func (r *UsersResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Count int               `json:"count"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Count = raw.Count
+	r.Items = make([]User, len(raw.Items))
+	for i, item := range raw.Items {
+		if err := decodeUser(item, &r.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeUser decodes user from object or from id
+func decodeUser(data []byte, u *User) error {
+	if len(data) != 0 && data[0] != '{' {
+		return json.Unmarshal(data, &u.ID)
+	}
+	return json.Unmarshal(data, u)
+}
+
+func (u Users) Get(fields UsersGetFields) ([]User, error) {
+	return u.GetContext(context.Background(), fields)
+}
+
+// GetContext returns users by ids or screen names, ids are
+// requested with chunks of maxUsersGetIDs
+func (u Users) GetContext(ctx context.Context, fields UsersGetFields) ([]User, error) {
+	ids := fields.UserIDs
+	var users []User
+	for {
+		chunk := ids
+		if len(chunk) > maxUsersGetIDs {
+			chunk = chunk[:maxUsersGetIDs]
+		}
+		fields.UserIDs = chunk
+		var result []User
+		if err := u.DecodeContext(ctx, u.Request(methodUsersGet, fields), &result); err != nil {
+			return users, err
+		}
+		users = append(users, result...)
+		if ids = ids[len(chunk):]; len(ids) == 0 {
+			return users, nil
+		}
+	}
+}
+
+// UsersSort is order of users.search results
+type UsersSort int
+
+const (
+	UsersSortPopularity   UsersSort = 0
+	UsersSortRegistration UsersSort = 1
+)
+
+type UsersSearchFields struct {
+	Query    string    `url:"q,omitempty"`
+	Sort     UsersSort `url:"sort,omitempty"`
+	Offset   int       `url:"offset,omitempty"`
+	Count    int       `url:"count,omitempty"`
+	Fields   string    `url:"fields,omitempty"`
+	City     int       `url:"city,omitempty"`
+	Country  CountryID `url:"country,omitempty"`
+	Sex      Sex       `url:"sex,omitempty"`
+	Status   Relation  `url:"status,omitempty"`
+	AgeFrom  int       `url:"age_from,omitempty"`
+	AgeTo    int       `url:"age_to,omitempty"`
+	Online   Bool      `url:"online,omitempty"`
+	HasPhoto Bool      `url:"has_photo,omitempty"`
+	GroupID  int       `url:"group_id,omitempty"`
+}
+
+func (u Users) Search(fields UsersSearchFields) (UsersResult, error) {
+	return u.SearchContext(context.Background(), fields)
+}
+
+// SearchContext returns page of users matching filters,
+// vk returns at most 1000 users for every query
+func (u Users) SearchContext(ctx context.Context, fields UsersSearchFields) (result UsersResult, err error) {
+	return result, u.DecodeContext(ctx, u.Request(methodUsersSearch, fields), &result)
+}
+
+type UsersFollowersFields struct {
+	UserID   int    `url:"user_id,omitempty"`
+	Offset   int    `url:"offset,omitempty"`
+	Count    int    `url:"count,omitempty"`
+	Fields   string `url:"fields,omitempty"`
+	NameCase string `url:"name_case,omitempty"`
+}
+
+func (u Users) GetFollowers(fields UsersFollowersFields) (UsersResult, error) {
+	return u.GetFollowersContext(context.Background(), fields)
+}
+
+// GetFollowersContext returns page of followers of user,
+// only ids are set if no fields are requested
+func (u Users) GetFollowersContext(ctx context.Context, fields UsersFollowersFields) (result UsersResult, err error) {
+	return result, u.DecodeContext(ctx, u.Request(methodUsersGetFollowers, fields), &result)
+}
+
+type UsersSubscriptionsFields struct {
+	UserID int    `url:"user_id,omitempty"`
+	Offset int    `url:"offset,omitempty"`
+	Count  int    `url:"count,omitempty"`
+	Fields string `url:"fields,omitempty"`
+}
+
+// SubscriptionsResult is page of subscriptions,
+// Count is total count of users and communities
+type SubscriptionsResult struct {
+	Count  int
+	Users  []User
+	Groups []Group
+}
+
+// itemTypeProfile is type of user item in lists of users and communities
+const itemTypeProfile = "profile"
+
+func (r *SubscriptionsResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Count int               `json:"count"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Count = raw.Count
+	for _, item := range raw.Items {
+		var kind struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(item, &kind); err != nil {
+			return err
+		}
+		if kind.Type == itemTypeProfile {
+			var user User
+			if err := json.Unmarshal(item, &user); err != nil {
+				return err
+			}
+			r.Users = append(r.Users, user)
+			continue
+		}
+		var group Group
+		if err := json.Unmarshal(item, &group); err != nil {
+			return err
+		}
+		r.Groups = append(r.Groups, group)
+	}
+	return nil
+}
+
+func (u Users) GetSubscriptions(fields UsersSubscriptionsFields) (SubscriptionsResult, error) {
+	return u.GetSubscriptionsContext(context.Background(), fields)
+}
+
+// GetSubscriptionsContext returns page of users and communities
+// that user is subscribed to
+func (u Users) GetSubscriptionsContext(ctx context.Context, fields UsersSubscriptionsFields) (result SubscriptionsResult, err error) {
+	request := u.Request(methodUsersGetSubscriptions, struct {
+		UsersSubscriptionsFields
+		Extended Bool `url:"extended"`
+	}{fields, true})
+	return result, u.DecodeContext(ctx, request, &result)
+}
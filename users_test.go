@@ -1,6 +1,9 @@
 package vk
 
 import (
+	"context"
+	"strings"
+	"sync"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -20,3 +23,149 @@ func TestUserStringer(t *testing.T) {
 		So(Country{0, "Россия"}.Is(Russia), ShouldBeFalse)
 	})
 }
+
+// requestsMock records requests and responds with
+// responses in order, repeating last one
+type requestsMock struct {
+	mux       sync.Mutex
+	requests  []Request
+	responses []string
+}
+
+func (m *requestsMock) Do(req Request) (*Response, error) {
+	return m.DoContext(context.Background(), req)
+}
+
+func (m *requestsMock) DoContext(ctx context.Context, req Request) (*Response, error) {
+	m.mux.Lock()
+	n := len(m.requests)
+	m.requests = append(m.requests, req)
+	if n >= len(m.responses) {
+		n = len(m.responses) - 1
+	}
+	m.mux.Unlock()
+	return newApiMock(m.responses[n], nil).DoContext(ctx, req)
+}
+
+func TestUsers(t *testing.T) {
+	Convey("Users", t, func() {
+		Convey(methodUsersGet, func() {
+			mock := &requestsMock{responses: []string{
+				`{"response": [{"id": 1, "first_name": "Pavel"}, {"id": 2}]}`,
+				`{"response": [{"id": 3, "first_name": "Ivan"}]}`,
+			}}
+			u := Users{record(mock, DefaultFactory)}
+			users, err := u.Get(UsersGetFields{UserIDs: []string{"durov", "2"}, Fields: "city"})
+			So(err, ShouldBeNil)
+			So(users, ShouldHaveLength, 2)
+			So(users[0].FirstName, ShouldEqual, "Pavel")
+			So(mock.requests, ShouldHaveLength, 1)
+			So(mock.requests[0].Method, ShouldEqual, methodUsersGet)
+			So(mock.requests[0].Values.Get("user_ids"), ShouldEqual, "durov,2")
+			So(mock.requests[0].Values.Get("fields"), ShouldEqual, "city")
+
+			Convey("Chunks", func() {
+				mock.requests = nil
+				ids := make([]int, maxUsersGetIDs+1)
+				for i := range ids {
+					ids[i] = i + 1
+				}
+				users, err := u.Get(UsersGetFields{UserIDs: IDs(ids...)})
+				So(err, ShouldBeNil)
+				So(users, ShouldHaveLength, 3)
+				So(users[2].FirstName, ShouldEqual, "Ivan")
+				So(mock.requests, ShouldHaveLength, 2)
+				So(strings.Count(mock.requests[0].Values.Get("user_ids"), ","), ShouldEqual, maxUsersGetIDs-1)
+				So(mock.requests[1].Values.Get("user_ids"), ShouldEqual, "1001")
+			})
+			Convey("Current user", func() {
+				mock.requests = nil
+				_, err := u.Get(UsersGetFields{})
+				So(err, ShouldBeNil)
+				So(mock.requests, ShouldHaveLength, 1)
+				So(mock.requests[0].Values.Get("user_ids"), ShouldBeBlank)
+			})
+		})
+		Convey(methodUsersSearch, func() {
+			mock := &requestsMock{responses: []string{
+				`{"response": {"count": 1500, "items": [{"id": 1, "sex": 1}]}}`,
+			}}
+			u := Users{record(mock, DefaultFactory)}
+			result, err := u.Search(UsersSearchFields{
+				Query:    "Anna",
+				City:     2,
+				Country:  Russia,
+				Sex:      Female,
+				Status:   RelationActiveSearch,
+				AgeFrom:  18,
+				AgeTo:    25,
+				Offset:   100,
+				Count:    50,
+				HasPhoto: true,
+			})
+			So(err, ShouldBeNil)
+			So(result.Count, ShouldEqual, 1500)
+			So(result.Items[0].Sex, ShouldEqual, Female)
+			values := mock.requests[0].Values
+			So(values.Get("q"), ShouldEqual, "Anna")
+			So(values.Get("city"), ShouldEqual, "2")
+			So(values.Get("country"), ShouldEqual, "1")
+			So(values.Get("sex"), ShouldEqual, "1")
+			So(values.Get("status"), ShouldEqual, "6")
+			So(values.Get("age_from"), ShouldEqual, "18")
+			So(values.Get("age_to"), ShouldEqual, "25")
+			So(values.Get("offset"), ShouldEqual, "100")
+			So(values.Get("count"), ShouldEqual, "50")
+			So(values.Get("has_photo"), ShouldEqual, "1")
+			So(values.Get("online"), ShouldBeBlank)
+		})
+		Convey(methodUsersGetFollowers, func() {
+			Convey("Ids", func() {
+				mock := &requestsMock{responses: []string{`{"response": {"count": 10, "items": [5, 6]}}`}}
+				u := Users{record(mock, DefaultFactory)}
+				result, err := u.GetFollowers(UsersFollowersFields{UserID: 1, Offset: 8})
+				So(err, ShouldBeNil)
+				So(result.Count, ShouldEqual, 10)
+				So(result.Items, ShouldHaveLength, 2)
+				So(result.Items[1].ID, ShouldEqual, 6)
+				So(mock.requests[0].Values.Get("user_id"), ShouldEqual, "1")
+				So(mock.requests[0].Values.Get("offset"), ShouldEqual, "8")
+			})
+			Convey("Objects", func() {
+				mock := &requestsMock{responses: []string{`{"response": {"count": 1, "items": [{"id": 5, "last_name": "Ivanov"}]}}`}}
+				u := Users{record(mock, DefaultFactory)}
+				result, err := u.GetFollowers(UsersFollowersFields{Fields: "sex"})
+				So(err, ShouldBeNil)
+				So(result.Items[0].LastName, ShouldEqual, "Ivanov")
+			})
+			Convey("Error", func() {
+				u := Users{record(newApiMock("", ErrAuthFailed), DefaultFactory)}
+				_, err := u.GetFollowers(UsersFollowersFields{})
+				So(err, ShouldEqual, ErrAuthFailed)
+			})
+		})
+		Convey(methodUsersGetSubscriptions, func() {
+			mock := &requestsMock{responses: []string{`{"response": {"count": 120, "items": [
+				{"id": 1, "name": "Group", "screen_name": "club1", "type": "page"},
+				{"id": 2, "first_name": "Pavel", "type": "profile"},
+				{"id": 3, "name": "Event", "type": "event"}
+			]}}`}}
+			u := Users{record(mock, DefaultFactory)}
+			result, err := u.GetSubscriptions(UsersSubscriptionsFields{UserID: 1, Offset: 20, Count: 3})
+			So(err, ShouldBeNil)
+			So(result.Count, ShouldEqual, 120)
+			So(result.Users, ShouldHaveLength, 1)
+			So(result.Users[0].FirstName, ShouldEqual, "Pavel")
+			So(result.Groups, ShouldHaveLength, 2)
+			So(result.Groups[0].Slug, ShouldEqual, "club1")
+			values := mock.requests[0].Values
+			So(values.Get("extended"), ShouldEqual, "1")
+			So(values.Get("offset"), ShouldEqual, "20")
+			So(values.Get("count"), ShouldEqual, "3")
+		})
+		Convey("Client", func() {
+			So(New().Users.RequestFactory, ShouldNotBeNil)
+			So(NewWithToken("token").Users.Request(methodUsersGet, nil).Token, ShouldEqual, "token")
+		})
+	})
+}
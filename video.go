@@ -2,11 +2,14 @@ package vk
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 )
 
 const (
 	methodVideoGet = "video.get"
+	// maxVideoGetCount is maximum page size of video.get
+	maxVideoGetCount = 200
 )
 
 type Video struct {
@@ -62,5 +65,15 @@ type VideoGetResult struct {
 }
 
 func (v Video) Get(fields VideoGetFields) (result VideoGetResult, err error) {
-	return result, v.Decode(v.Request(methodVideoGet, fields), &result)
+	return v.GetContext(context.Background(), fields)
+}
+
+func (v Video) GetContext(ctx context.Context, fields VideoGetFields) (result VideoGetResult, err error) {
+	return result, v.DecodeContext(ctx, v.Request(methodVideoGet, fields), &result)
+}
+
+// IterateVideos returns Iterator over VideoItem items,
+// Count of fields is used as page size
+func (v Video) IterateVideos(ctx context.Context, fields VideoGetFields) *Iterator {
+	return v.Iterate(ctx, v.Request(methodVideoGet, fields), fields.Count)
 }
@@ -1,11 +1,248 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
-	. "github.com/cydev/vk"
+	. "github.com/ernado-legacy/vk"
+	"golang.org/x/term"
 )
 
+const (
+	flowCode     = "code"
+	flowImplicit = "implicit"
+	callbackPath = "/callback"
+	stateSize    = 16
+)
+
+var (
+	appID        int64
+	scope        string
+	flow         string
+	clientSecret string
+	listen       string
+	storePath    string
+	serviceToken string
+	timeout      time.Duration
+)
+
+func init() {
+	flag.Int64Var(&appID, "app", 3897553, "application id")
+	flag.StringVar(&scope, "scope", "offline,messages", "comma separated permissions or bitmask")
+	flag.StringVar(&flow, "flow", flowImplicit, "authorization flow, code or implicit")
+	flag.StringVar(&clientSecret, "secret", "", "application secret, required for code flow, defaults to VK_SECRET")
+	flag.StringVar(&listen, "listen", "localhost:8080", "address of redirect listener for code flow")
+	flag.StringVar(&storePath, "store", "vk-tokens.json", "token store file")
+	flag.StringVar(&serviceToken, "service-token", "", "service token for secure.checkToken, defaults to VK_SERVICE_TOKEN")
+	flag.DurationVar(&timeout, "timeout", time.Minute*5, "authorization timeout")
+}
+
+// newState returns random oauth state
+func newState() (string, error) {
+	state := make([]byte, stateSize)
+	if _, err := rand.Read(state); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(state), nil
+}
+
+// codeFlow waits for redirect with authorization code
+// on local listener and exchanges it for token
+func codeFlow(ctx context.Context, auth Auth) (Token, error) {
+	if len(clientSecret) == 0 {
+		return Token{}, errors.New("secret is required for code flow")
+	}
+	state, err := newState()
+	if err != nil {
+		return Token{}, err
+	}
+	auth.State = state
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return Token{}, err
+	}
+	auth.RedirectURI = "http://" + l.Addr().String() + callbackPath
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		// redirect that was not initiated by this flow is rejected
+		got := r.URL.Query().Get("state")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(state)) != 1 {
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return
+		}
+		code := r.URL.Query().Get("code")
+		if len(code) == 0 {
+			// redirect without code has error description
+			_, err := ParseRedirect(r.URL.String())
+			if err == nil {
+				err = ErrNoToken
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			select {
+			case errs <- err:
+			default:
+			}
+			return
+		}
+		fmt.Fprintln(w, "authorized, you can close this page")
+		select {
+		case codes <- code:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(l)
+	defer server.Close()
+
+	fmt.Println("open in browser:")
+	fmt.Println(auth.CodeURL())
+
+	select {
+	case code := <-codes:
+		return auth.ExchangeContext(ctx, code, clientSecret)
+	case err := <-errs:
+		return Token{}, err
+	case <-ctx.Done():
+		return Token{}, ctx.Err()
+	}
+}
+
+// implicitFlow reads url of blank.html page that user
+// was redirected to and parses token from it
+func implicitFlow(ctx context.Context, auth Auth) (Token, error) {
+	fmt.Println("open in browser:")
+	fmt.Println(auth.URL())
+	fmt.Print("paste url of page you were redirected to: ")
+
+	type result struct {
+		line string
+		err  error
+	}
+	lines := make(chan result, 1)
+	go func() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		lines <- result{line, err}
+	}()
+	select {
+	case r := <-lines:
+		if r.err != nil && len(r.line) == 0 {
+			return Token{}, r.err
+		}
+		return ParseRedirect(strings.TrimSpace(r.line))
+	case <-ctx.Done():
+		fmt.Println()
+		return Token{}, ctx.Err()
+	}
+}
+
+// readPassphrase returns token store passphrase from VK_PASSPHRASE
+// or asks for it without echo, so it is not exposed in process list
+func readPassphrase() (string, error) {
+	if passphrase := os.Getenv("VK_PASSPHRASE"); len(passphrase) != 0 {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("passphrase is required, set VK_PASSPHRASE")
+	}
+	fmt.Print("token store passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) == 0 {
+		return "", errors.New("passphrase is required")
+	}
+	return string(passphrase), nil
+}
+
+func authorize(ctx context.Context) error {
+	passphrase, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+	auth := Auth{ID: appID, Scope: ParseScope(scope)}
+
+	var token Token
+	switch flow {
+	case flowCode:
+		token, err = codeFlow(ctx, auth)
+	case flowImplicit:
+		token, err = implicitFlow(ctx, auth)
+	default:
+		return fmt.Errorf("unknown flow %q", flow)
+	}
+	if err != nil {
+		return err
+	}
+
+	granted := auth.Scope
+	if len(token.AccessToken) != 0 {
+		var info TokenInfo
+		if len(serviceToken) != 0 {
+			secure := NewWithToken(serviceToken, WithTokenKind(serviceToken, TokenService)).Secure
+			info, err = secure.InspectToken(ctx, token.AccessToken)
+		} else {
+			info, err = NewWithToken(token.AccessToken).Account.Inspect(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("token validation failed: %w", err)
+		}
+		var required []Permission
+		for p := range auth.Scope {
+			required = append(required, p)
+		}
+		if err = info.Require(required...); err != nil {
+			return err
+		}
+		token.UserID = info.UserID
+		granted = info.Scope
+	}
+
+	store, err := NewFileStore(storePath, passphrase)
+	if err != nil {
+		return err
+	}
+	for _, t := range StoredTokens(token, granted) {
+		if err = store.Put(t); err != nil {
+			return err
+		}
+		fmt.Println("saved token of", t.Identity, "to", storePath)
+	}
+	return nil
+}
+
+// envDefault sets v to value of environment variable if v is empty,
+// so secrets are not printed as flag defaults
+func envDefault(v *string, key string) {
+	if len(*v) == 0 {
+		*v = os.Getenv(key)
+	}
+}
+
 func main() {
-	fmt.Println(Auth{Scope: NewScope(PermOffline, "messages"), ID: 3897553}.URL())
+	flag.Parse()
+	envDefault(&clientSecret, "VK_SECRET")
+	envDefault(&serviceToken, "VK_SERVICE_TOKEN")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := authorize(ctx); err != nil {
+		fmt.Println("error:", err)
+		os.Exit(2)
+	}
 }
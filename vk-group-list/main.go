@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"github.com/cydev/vk"
+	"github.com/ernado-legacy/vk"
+	"github.com/ernado-legacy/vk/internal/cli"
 	"github.com/spf13/viper"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -16,43 +20,77 @@ func init() {
 	viper.SetEnvPrefix("vk")
 	viper.BindEnv("token")
 	viper.BindEnv("id")
+	// comma separated tokens, requests are spread between them
+	viper.BindEnv("tokens")
+	// resumable parallel dump is used if checkpoint is set
+	viper.BindEnv("checkpoint")
+	viper.BindEnv("workers")
 }
 
-func getAllUsers(api *vk.Client) (err error) {
-	var (
-		gotUsers int
-		n        int
-		users    []vk.User
-	)
-	fields := vk.GroupGetFields{
-		Fields:  vk.UserFields,
-		Offset:  0,
-		GroupID: groupID,
+func newClient() (*vk.Client, error) {
+	if tokens := viper.GetString("tokens"); len(tokens) != 0 {
+		return vk.NewWithPool(vk.NewTokenPool(strings.Split(tokens, ",")...)), nil
 	}
-	for {
-		fields.Offset = gotUsers
-		users, n, err = api.Groups.GetBatch(fields)
-		if err != nil {
+	// token saved by vk-auth is used if token is not set
+	return cli.NewClient(token)
+}
+
+func getAllUsers(api *vk.Client) error {
+	it := api.Groups.IterateMembers(context.Background(), vk.GroupSearchFields{
+		ID:     groupID,
+		Fields: vk.UserFields,
+	})
+	it.SetBatch(25)
+	n := 0
+	for it.Next() {
+		var member vk.GroupMember
+		if err := it.Decode(&member); err != nil {
 			return err
 		}
-		gotUsers += len(users)
-		fmt.Println("got", gotUsers, "of", n)
-		if gotUsers >= n {
-			// got all users
-			fmt.Println("got all", gotUsers)
-			return nil
+		n++
+		if n%1000 == 0 {
+			fmt.Println("got", n, "of", it.Total())
 		}
 	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	fmt.Println("got all", n)
+	return nil
+}
+
+func dumpAllUsers(api *vk.Client) error {
+	dumper := vk.NewDumper(api.Groups, groupID)
+	dumper.SetFields(vk.UserFields)
+	dumper.SetWorkers(viper.GetInt("workers"))
+	dumper.SetCheckpoint(viper.GetString("checkpoint"))
+	dumper.SetProgress(func(p vk.DumpProgress) {
+		fmt.Println("got", p.Members, "of", p.Total, "ranges", p.Completed, "of", p.Ranges)
+	})
+	return dumper.Run(context.Background(), func(vk.User) error {
+		return nil
+	})
 }
 
 func main() {
 	viper.AutomaticEnv()
 	groupID = viper.GetInt("id")
 	token = viper.GetString("token")
-	fmt.Println(groupID, token)
-	api := vk.NewWithToken(token)
+	fmt.Println(groupID)
+	api, err := newClient()
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(2)
+	}
 	start := time.Now()
-	getAllUsers(api)
+	load := getAllUsers
+	if len(viper.GetString("checkpoint")) != 0 {
+		load = dumpAllUsers
+	}
+	if err := load(api); err != nil {
+		fmt.Println("error:", err)
+		os.Exit(2)
+	}
 	end := time.Now()
 	fmt.Println("users loaded", end.Sub(start))
 }
@@ -3,12 +3,12 @@ package main
 import (
 	"fmt"
 	"log"
-	"net/url"
 	"os"
 	"strconv"
 	"time"
 
-	. "github.com/cydev/vk"
+	. "github.com/ernado-legacy/vk"
+	"github.com/ernado-legacy/vk/internal/cli"
 )
 
 type BlankResponse struct {
@@ -17,18 +17,18 @@ type BlankResponse struct {
 
 func main() {
 	ownerID, _ := strconv.Atoi(os.Args[1])
-	token := os.Args[2]
 	offset := os.Args[3]
-
-	args := url.Values{}
-	args.Add("count", "200")
-	args.Add("offset", offset)
-
-	req := Request{
-		Token:  token,
-		Method: "messages.getDialogs",
-		Values: args,
+	// token saved by vk-auth is used if token argument is empty
+	api, err := cli.NewClient(os.Args[2])
+	if err != nil {
+		log.Fatal(err)
 	}
+	r := api.Users.Resource
+
+	req := r.Request("messages.getDialogs", struct {
+		Count  int    `url:"count"`
+		Offset string `url:"offset"`
+	}{200, offset})
 
 	type Dialogs struct {
 		Items []struct {
@@ -42,7 +42,7 @@ func main() {
 
 	var dialogs Dialogs
 
-	res, err := DefaultClient.Do(req)
+	res, err := r.Do(req)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -58,16 +58,12 @@ func main() {
 
 		if dialog.Message.Out != 1 {
 			fmt.Println("leaving chat")
-			args := url.Values{}
-			args.Add("chat_id", strconv.Itoa(dialog.Message.ID))
-			args.Add("user_id", "214321467")
-			args.Add("count", "10000")
 			response := BlankResponse{}
-			res, err := DefaultClient.Do(Request{
-				Token:  token,
-				Method: "messages.removeChatUser",
-				Values: args,
-			})
+			res, err := r.Do(r.Request("messages.removeChatUser", struct {
+				ChatID int `url:"chat_id"`
+				UserID int `url:"user_id"`
+				Count  int `url:"count"`
+			}{dialog.Message.ID, 214321467, 10000}))
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -2,10 +2,10 @@ package main
 
 import (
 	"flag"
-
 	"fmt"
-	"github.com/cydev/vk"
 	"os"
+
+	"github.com/ernado-legacy/vk/internal/cli"
 )
 
 var (
@@ -20,7 +20,12 @@ func init() {
 
 func main() {
 	flag.Parse()
-	api := vk.NewWithToken(token)
+	// token saved by vk-auth is used if token is not set
+	api, err := cli.NewClient(token)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(2)
+	}
 	groups, err := api.Groups.GetForUser(userID)
 	if err != nil {
 		fmt.Println("error:", err)
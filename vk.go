@@ -1,14 +1,19 @@
 package vk
 
 import (
+	"context"
+	"log"
 	"net/url"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-querystring/query"
 )
 
 var (
+	// Debug enables logging to stderr for clients created after it is set
 	Debug = false
 )
 
@@ -22,6 +27,14 @@ const (
 	paramDisplay      = "display"
 	paramHTTPS        = "https"
 	paramResponseType = "response_type"
+	paramClientSecret = "client_secret"
+	paramGroupIDs     = "group_ids"
+	paramState        = "state"
+	paramGrantType    = "grant_type"
+	paramLang         = "lang"
+	paramTestMode     = "test_mode"
+	paramCaptchaSID   = "captcha_sid"
+	paramCaptchaKey   = "captcha_key"
 
 	oauthHost         = "oauth.vk.com"
 	oauthDisplay      = "page"
@@ -29,6 +42,9 @@ const (
 	oauthResponseType = "token"
 	oauthRedirectURI  = "https://oauth.vk.com/blank.html"
 	oauthScheme       = "https"
+	oauthTokenPath    = "/access_token"
+	oauthResponseCode = "code"
+	oauthGrantClient  = "client_credentials"
 
 	defaultHost    = "api.vk.com"
 	defaultPath    = "/method/"
@@ -37,10 +53,22 @@ const (
 	defaultMethod  = "GET"
 	defaultHTTPS   = "1"
 
-	maxRequestsPerSecond = 3
-	minimumRate          = time.Second / maxRequestsPerSecond
-	methodExecute        = "execute"
-	maxRequestRepeat     = 10
+	defaultTestMode = "1"
+	// defaultPostThreshold keeps urls well below common length limits
+	defaultPostThreshold = 2048
+	contentTypeForm      = "application/x-www-form-urlencoded"
+
+	maxRequestsPerSecond      = 3
+	minimumRate               = time.Second / maxRequestsPerSecond
+	maxGroupRequestsPerSecond = 20
+	minimumGroupRate          = time.Second / maxGroupRequestsPerSecond
+	// maxServiceRequestsPerSecond is limit of secure methods
+	// for applications with smallest audience
+	maxServiceRequestsPerSecond = 5
+	minimumServiceRate          = time.Second / maxServiceRequestsPerSecond
+	methodExecute               = "execute"
+	maxRequestRepeat            = 10
+	maxCaptchaAttempts          = 3
 )
 
 // int64s formats int64 as base10 string
@@ -48,16 +76,36 @@ func int64s(v int64) string {
 	return strconv.FormatInt(v, 10)
 }
 
+// joinInts formats integers as comma separated list
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
 // Client for vk api
 type Client struct {
 	httpClient HTTPClient
+	limiter    Limiter
+	kinds      map[string]TokenKind
+	retry      RetryPolicy
+	logger     Logger
+	captcha    CaptchaSolver
+	endpoint   endpoint
+	pool       *TokenPool
 	Groups     Groups
 	Video      Video
+	Users      Users
+	Account    Account
+	Secure     Secure
 }
 
 // APIClient preforms request and fills
 type APIClient interface {
 	Do(request Request) (*Response, error)
+	DoContext(ctx context.Context, request Request) (*Response, error)
 }
 
 // Request to vk api
@@ -73,6 +121,56 @@ func (c *Client) SetHTTPClient(httpClient HTTPClient) {
 	c.httpClient = httpClient
 }
 
+// SetCaptchaSolver sets solver that is invoked on ErrCaptchaNeeded,
+// nil disables captcha handling
+func (c *Client) SetCaptchaSolver(solver CaptchaSolver) {
+	c.captcha = solver
+}
+
+// SetLogger sets logger of client events, nil disables logging
+func (c *Client) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	c.logger = logger
+}
+
+// SetRetryPolicy sets policy of repeating failed requests, nil disables retries
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	if policy == nil {
+		policy = NoRetry{}
+	}
+	c.retry = policy
+}
+
+// SetLimiter sets request rate limiter, nil disables limiting.
+// Kinds of tokens set by SetTokenKind are passed to limiter.
+func (c *Client) SetLimiter(limiter Limiter) {
+	c.limiter = limiter
+	if l, ok := limiter.(kindLimiter); ok {
+		for token, kind := range c.kinds {
+			l.SetKind(token, kind)
+		}
+	}
+}
+
+// Limiter returns request rate limiter of client, nil if disabled
+func (c *Client) Limiter() Limiter {
+	return c.limiter
+}
+
+// SetTokenKind sets kind of access token, so limiter applies rate
+// of that kind to it. Token with unknown kind is limited as user one.
+func (c *Client) SetTokenKind(token string, kind TokenKind) {
+	if c.kinds == nil {
+		c.kinds = make(map[string]TokenKind)
+	}
+	c.kinds[token] = kind
+	if l, ok := c.limiter.(kindLimiter); ok {
+		l.SetKind(token, kind)
+	}
+}
+
 // Auth is helper struct for application authentication
 type Auth struct {
 	ID           int64
@@ -80,6 +178,15 @@ type Auth struct {
 	RedirectURI  string
 	ResponseType string
 	Display      string
+	// GroupIDs requests community tokens instead of user token
+	GroupIDs []int
+	// State is returned to redirect uri unchanged, random
+	// value protects code flow from forged redirects
+	State string
+	// BaseURL of oauth server, "https://oauth.vk.com" by default
+	BaseURL string
+	// HTTPClient performs token requests, default client is used if nil
+	HTTPClient HTTPClient
 }
 
 type RequestFactory interface {
@@ -105,10 +212,7 @@ func (f Factory) Request(method string, arguments interface{}) (request Request)
 
 // URL returns redirect url for application authentication
 func (a Auth) URL() string {
-	u := url.URL{}
-	u.Host = oauthHost
-	u.Scheme = oauthScheme
-	u.Path = oauthPath
+	u := a.oauthURL(oauthPath)
 
 	if len(a.RedirectURI) == 0 {
 		a.RedirectURI = oauthRedirectURI
@@ -127,31 +231,81 @@ func (a Auth) URL() string {
 	values.Add(paramRedirectURI, a.RedirectURI)
 	values.Add(paramVersion, defaultVersion)
 	values.Add(paramDisplay, a.Display)
+	if len(a.GroupIDs) != 0 {
+		values.Add(paramGroupIDs, joinInts(a.GroupIDs))
+	}
+	if len(a.State) != 0 {
+		values.Add(paramState, a.State)
+	}
 	u.RawQuery = values.Encode()
 
 	return u.String()
 }
 
 // New creates and returns default vk api client
-func New() *Client {
-	c := new(Client)
-	c.SetHTTPClient(defaultHTTPClient)
-	resource := Resource{}
-	resource.APIClient = c
-	resource.RequestFactory = DefaultFactory
-	c.Groups = Groups{resource}
+func New(options ...Option) *Client {
+	return newClient(DefaultFactory, options)
+}
+
+// NewWithToken creates vk api client that performs
+// all resource requests with provided access token
+func NewWithToken(token string, options ...Option) *Client {
+	return newClient(Factory{token}, options)
+}
+
+// NewWithServiceToken obtains service token of application
+// with client credentials flow and creates client that performs
+// all resource requests with it
+func NewWithServiceToken(ctx context.Context, auth Auth, clientSecret string, options ...Option) (*Client, error) {
+	token, err := auth.ServiceTokenContext(ctx, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	c := NewWithToken(token.AccessToken, options...)
+	c.SetTokenKind(token.AccessToken, TokenService)
+	return c, nil
+}
+
+// NewWithPool creates vk api client that performs resource
+// requests with tokens of pool, reporting their results to pool.
+// Request that failed with rate limit is repeated with other
+// token of pool, if retry policy allows repeating it.
+func NewWithPool(pool *TokenPool, options ...Option) *Client {
+	c := newClient(pool, options)
+	c.pool = pool
+	for _, token := range pool.tokens {
+		c.SetTokenKind(token.token, pool.kind)
+	}
 	return c
 }
 
-func NewWithToken(token string) *Client {
+func newClient(factory RequestFactory, options []Option) *Client {
 	c := new(Client)
 	c.SetHTTPClient(defaultHTTPClient)
+	c.SetLimiter(DefaultLimiter())
+	c.SetRetryPolicy(DefaultRetryPolicy)
+	c.SetLogger(nil)
+	if Debug {
+		c.SetLogger(StdLogger(log.New(os.Stderr, "vk: ", log.LstdFlags)))
+	}
+	c.endpoint = defaultEndpoint
+	for _, option := range options {
+		option(c)
+	}
 	resource := Resource{}
 	resource.APIClient = c
-	resource.RequestFactory = Factory{token}
+	resource.RequestFactory = factory
+	c.bind(resource)
+	return c
+}
+
+// bind sets resource for all api sections
+func (c *Client) bind(resource Resource) {
 	c.Video = Video{resource}
 	c.Groups = Groups{resource}
-	return c
+	c.Users = Users{resource}
+	c.Account = Account{resource}
+	c.Secure = Secure{resource}
 }
 
 var (
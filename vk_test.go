@@ -55,7 +55,51 @@ func TestAuthUrl(t *testing.T) {
 		gotURL, err := url.Parse(stringURL)
 		So(err, ShouldBeNil)
 		So(gotURL.Host, ShouldEqual, oauthHost)
-		shouldURL := "https://oauth.vk.com/authorize/?client_id=0&display=page&redirect_uri=https%3A%2F%2Foauth.vk.com%2Fblank.html&response_type=token&scope=groups%2Coffline&v=5.35"
+		shouldURL := "https://oauth.vk.com/authorize/?client_id=0&display=page&redirect_uri=https%3A%2F%2Foauth.vk.com%2Fblank.html&response_type=token&scope=groups%2Coffline&v=5.103"
 		So(shouldURL, ShouldEqual, gotURL.String())
 	})
 }
+
+func TestClientOptions(t *testing.T) {
+	Convey("Client options", t, func() {
+		var got *http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			w.Write([]byte(`{"response": 1}`))
+		}))
+		defer server.Close()
+
+		Convey("Defaults", func() {
+			client := New()
+			So(client.endpoint.URL.String(), ShouldEqual, "https://api.vk.com/method/")
+			So(client.endpoint.Version, ShouldEqual, defaultVersion)
+			So(client.Video.RequestFactory, ShouldNotBeNil)
+		})
+		Convey("Applied to request", func() {
+			client := NewWithToken("token",
+				WithBaseURL(server.URL+"/api/"),
+				WithVersion("5.131"),
+				WithLanguage("en"),
+				WithTestMode(true),
+				WithLimiter(nil),
+			)
+			_, err := client.Do(Request{Method: "users.get"})
+			So(err, ShouldBeNil)
+			So(got.URL.Path, ShouldEqual, "/api/users.get")
+			So(got.URL.Query().Get(paramVersion), ShouldEqual, "5.131")
+			So(got.URL.Query().Get(paramLang), ShouldEqual, "en")
+			So(got.URL.Query().Get(paramTestMode), ShouldEqual, "1")
+
+			Convey("Request lang has priority", func() {
+				values := url.Values{}
+				values.Set(paramLang, "ru")
+				_, err := client.Do(Request{Method: "users.get", Values: values})
+				So(err, ShouldBeNil)
+				So(got.URL.Query()[paramLang], ShouldResemble, []string{"ru"})
+			})
+		})
+		Convey("Invalid base url", func() {
+			So(func() { WithBaseURL("http://[::1") }, ShouldPanic)
+		})
+	})
+}
@@ -0,0 +1,421 @@
+// Package vkscript is builder of VKScript code for execute method
+package vkscript
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxCalls is vk limit of api calls in single execute
+const MaxCalls = 25
+
+var (
+	// ErrTooManyCalls is returned for script that can perform
+	// more api calls than allowed in single execute
+	ErrTooManyCalls = errors.New("vkscript: too many api calls")
+
+	identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	methodRegexp     = regexp.MustCompile(`^[A-Za-z]+\.[A-Za-z]+$`)
+)
+
+// Expr is VKScript expression
+type Expr interface {
+	// script writes code of expression to b
+	script(b *bytes.Buffer)
+	// calls returns count of api calls in expression
+	calls() int
+	// check returns error if expression can not be rendered safely
+	check() error
+}
+
+func checkIdentifier(name string) error {
+	if !identifierRegexp.MatchString(name) {
+		return fmt.Errorf("vkscript: invalid identifier %q", name)
+	}
+	return nil
+}
+
+// literal is JSON encoded value
+type literal []byte
+
+func (l literal) script(b *bytes.Buffer) { b.Write(l) }
+func (l literal) calls() int             { return 0 }
+func (l literal) check() error           { return nil }
+
+// Value returns literal of JSON encodable v, strings are escaped
+func Value(v interface{}) Expr {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return literal(data)
+}
+
+// Int returns integer literal
+func Int(v int) Expr {
+	return Value(v)
+}
+
+// String returns escaped string literal
+func String(s string) Expr {
+	return Value(s)
+}
+
+// Var is reference to variable
+type Var string
+
+func (v Var) script(b *bytes.Buffer) { b.WriteString(string(v)) }
+func (v Var) calls() int             { return 0 }
+func (v Var) check() error           { return checkIdentifier(string(v)) }
+
+// Args are named arguments of api call or fields of object
+type Args map[string]Expr
+
+// keys returns sorted keys, so code is deterministic
+func (a Args) keys() []string {
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (a Args) script(b *bytes.Buffer) {
+	b.WriteByte('{')
+	for i, k := range a.keys() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		String(k).script(b)
+		b.WriteByte(':')
+		a[k].script(b)
+	}
+	b.WriteByte('}')
+}
+
+func (a Args) calls() (n int) {
+	for _, v := range a {
+		n += v.calls()
+	}
+	return n
+}
+
+func (a Args) check() error {
+	for _, v := range a {
+		if err := v.check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Object returns object expression with provided fields
+func Object(fields Args) Expr {
+	return fields
+}
+
+type array []Expr
+
+func (a array) script(b *bytes.Buffer) {
+	b.WriteByte('[')
+	for i, v := range a {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		v.script(b)
+	}
+	b.WriteByte(']')
+}
+
+func (a array) calls() (n int) {
+	for _, v := range a {
+		n += v.calls()
+	}
+	return n
+}
+
+func (a array) check() error {
+	for _, v := range a {
+		if err := v.check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Array returns array expression
+func Array(items ...Expr) Expr {
+	return array(items)
+}
+
+type call struct {
+	method string
+	args   Args
+}
+
+func (c call) script(b *bytes.Buffer) {
+	b.WriteString("API.")
+	b.WriteString(c.method)
+	b.WriteByte('(')
+	c.args.script(b)
+	b.WriteByte(')')
+}
+
+func (c call) calls() int {
+	return 1 + c.args.calls()
+}
+
+func (c call) check() error {
+	if !methodRegexp.MatchString(c.method) {
+		return fmt.Errorf("vkscript: invalid method %q", c.method)
+	}
+	return c.args.check()
+}
+
+// Call returns api call of method with provided arguments
+func Call(method string, args Args) Expr {
+	return call{method, args}
+}
+
+// CallValues returns api call of method with values as string arguments
+func CallValues(method string, values url.Values) Expr {
+	args := Args{}
+	for k := range values {
+		args[k] = String(values.Get(k))
+	}
+	return Call(method, args)
+}
+
+type field struct {
+	value Expr
+	name  string
+}
+
+func (f field) script(b *bytes.Buffer) {
+	f.value.script(b)
+	b.WriteByte('.')
+	b.WriteString(f.name)
+}
+
+func (f field) calls() int {
+	return f.value.calls()
+}
+
+func (f field) check() error {
+	if err := checkIdentifier(f.name); err != nil {
+		return err
+	}
+	return f.value.check()
+}
+
+// Field returns field access expression
+func Field(value Expr, name string) Expr {
+	return field{value, name}
+}
+
+type binary struct {
+	op   string
+	a, b Expr
+}
+
+func (e binary) script(b *bytes.Buffer) {
+	b.WriteByte('(')
+	e.a.script(b)
+	b.WriteString(" " + e.op + " ")
+	e.b.script(b)
+	b.WriteByte(')')
+}
+
+func (e binary) calls() int {
+	return e.a.calls() + e.b.calls()
+}
+
+func (e binary) check() error {
+	if err := e.a.check(); err != nil {
+		return err
+	}
+	return e.b.check()
+}
+
+// Add returns a + b, that is sum of numbers
+// or concatenation of arrays and strings
+func Add(a, b Expr) Expr { return binary{"+", a, b} }
+
+// Sub returns a - b
+func Sub(a, b Expr) Expr { return binary{"-", a, b} }
+
+// Less returns a < b
+func Less(a, b Expr) Expr { return binary{"<", a, b} }
+
+// Equal returns a == b
+func Equal(a, b Expr) Expr { return binary{"==", a, b} }
+
+// And returns a && b
+func And(a, b Expr) Expr { return binary{"&&", a, b} }
+
+// Or returns a || b
+func Or(a, b Expr) Expr { return binary{"||", a, b} }
+
+// statement of script
+type statement interface {
+	script(b *bytes.Buffer, indent int)
+	calls() int
+	check() error
+}
+
+type assignment struct {
+	declare bool
+	name    Var
+	value   Expr
+}
+
+func (s assignment) script(b *bytes.Buffer, indent int) {
+	b.WriteString(strings.Repeat("\t", indent))
+	if s.declare {
+		b.WriteString("var ")
+	}
+	s.name.script(b)
+	b.WriteString(" = ")
+	s.value.script(b)
+	b.WriteString(";\n")
+}
+
+func (s assignment) calls() int {
+	return s.value.calls()
+}
+
+func (s assignment) check() error {
+	if err := s.name.check(); err != nil {
+		return err
+	}
+	return s.value.check()
+}
+
+type loop struct {
+	cond          Expr
+	maxIterations int
+	body          *Script
+}
+
+func (s loop) script(b *bytes.Buffer, indent int) {
+	b.WriteString(strings.Repeat("\t", indent))
+	b.WriteString("while ")
+	s.cond.script(b)
+	b.WriteString(" {\n")
+	s.body.script(b, indent+1)
+	b.WriteString(strings.Repeat("\t", indent))
+	b.WriteString("}\n")
+}
+
+func (s loop) calls() int {
+	return s.cond.calls()*(s.maxIterations+1) + s.body.Calls()*s.maxIterations
+}
+
+func (s loop) check() error {
+	if err := s.cond.check(); err != nil {
+		return err
+	}
+	return s.body.check()
+}
+
+type ret struct {
+	value Expr
+}
+
+func (s ret) script(b *bytes.Buffer, indent int) {
+	b.WriteString(strings.Repeat("\t", indent))
+	b.WriteString("return ")
+	s.value.script(b)
+	b.WriteString(";\n")
+}
+
+func (s ret) calls() int   { return s.value.calls() }
+func (s ret) check() error { return s.value.check() }
+
+// Script is builder of VKScript code for execute method.
+// Values are always escaped, identifiers and method names
+// are validated before code is rendered.
+type Script struct {
+	statements []statement
+}
+
+// Declare adds declaration of variable
+func (s *Script) Declare(name Var, value Expr) {
+	s.statements = append(s.statements, assignment{true, name, value})
+}
+
+// Set adds assignment to variable
+func (s *Script) Set(name Var, value Expr) {
+	s.statements = append(s.statements, assignment{false, name, value})
+}
+
+// While adds loop with body that is repeated while cond is true.
+// Loop must not do more than maxIterations iterations, that is
+// used to count api calls of script.
+func (s *Script) While(cond Expr, maxIterations int, body func(s *Script)) {
+	l := loop{cond: cond, maxIterations: maxIterations, body: new(Script)}
+	body(l.body)
+	s.statements = append(s.statements, l)
+}
+
+// Return adds return statement
+func (s *Script) Return(value Expr) {
+	s.statements = append(s.statements, ret{value})
+}
+
+// Calls returns maximum count of api calls performed by script
+func (s *Script) Calls() (n int) {
+	for _, v := range s.statements {
+		n += v.calls()
+	}
+	return n
+}
+
+func (s *Script) check() error {
+	for _, v := range s.statements {
+		if err := v.check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate returns error if script has invalid identifiers
+// or can exceed limit of api calls in single execute
+func (s *Script) Validate() error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if s.Calls() > MaxCalls {
+		return ErrTooManyCalls
+	}
+	return nil
+}
+
+func (s *Script) script(b *bytes.Buffer, indent int) {
+	for _, v := range s.statements {
+		v.script(b, indent)
+	}
+}
+
+// String returns code of script without validation
+func (s *Script) String() string {
+	b := new(bytes.Buffer)
+	s.script(b, 0)
+	return b.String()
+}
+
+// Code validates script and returns its code
+func (s *Script) Code() (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
@@ -0,0 +1,76 @@
+package vkscript
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestScript(t *testing.T) {
+	Convey("VKScript", t, func() {
+		Convey("Render", func() {
+			const (
+				i     Var = "i"
+				items Var = "items"
+			)
+			s := new(Script)
+			s.Declare(i, Int(0))
+			s.Declare(items, Array())
+			s.While(Less(i, Int(3)), 3, func(s *Script) {
+				s.Set(items, Add(items, Array(Call("users.get", Args{"user_ids": i, "fields": String("sex")}))))
+				s.Set(i, Add(i, Int(1)))
+			})
+			s.Return(Object(Args{"items": items, "count": Field(items, "length")}))
+			code, err := s.Code()
+			So(err, ShouldBeNil)
+			So(code, ShouldEqual, `var i = 0;
+var items = [];
+while (i < 3) {
+	items = (items + [API.users.get({"fields":"sex","user_ids":i})]);
+	i = (i + 1);
+}
+return {"count":items.length,"items":items};
+`)
+			So(s.Calls(), ShouldEqual, 3)
+		})
+		Convey("Values are escaped", func() {
+			s := new(Script)
+			s.Return(Call("users.get", Args{"fields": String(`sex"}); API.wall.post({"message": "x`)}))
+			code, err := s.Code()
+			So(err, ShouldBeNil)
+			So(s.Calls(), ShouldEqual, 1)
+			So(code, ShouldEqual, `return API.users.get({"fields":"sex\"}); API.wall.post({\"message\": \"x"});`+"\n")
+		})
+		Convey("Request", func() {
+			values := url.Values{}
+			values.Set("user_ids", "1,2")
+			s := new(Script)
+			s.Return(CallValues("users.get", values))
+			So(s.String(), ShouldEqual, `return API.users.get({"user_ids":"1,2"});`+"\n")
+		})
+		Convey("Invalid identifiers", func() {
+			for _, e := range []Expr{
+				Var("a; API.wall.post()"),
+				Field(Var("a"), "b()"),
+				Call("wall.post({});API.users.get", Args{}),
+				Object(Args{"a": Var("1")}),
+			} {
+				s := new(Script)
+				s.Return(e)
+				_, err := s.Code()
+				So(err, ShouldNotBeNil)
+			}
+		})
+		Convey("Calls are limited", func() {
+			s := new(Script)
+			s.While(Value(true), MaxCalls, func(s *Script) {
+				s.Set("a", Call("users.get", Args{}))
+			})
+			So(s.Calls(), ShouldEqual, MaxCalls)
+			So(s.Validate(), ShouldBeNil)
+			s.Return(Call("users.get", Args{}))
+			So(s.Validate(), ShouldEqual, ErrTooManyCalls)
+		})
+	})
+}